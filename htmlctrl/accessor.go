@@ -0,0 +1,78 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// Accessor returns a JQuery object associated with a value that is reached through get and set instead of a
+// pointer, such as one that a type only exposes through methods. kind is the kind of the value, which must be a
+// bool, int, float64, or string, and the control is the one that function creates. A non-nil error is returned in
+// the event the conversion fails, including if get returns something that can't be converted to kind.
+//
+// The control starts out with the value from get and set is called with each new value, as a bool, int, float64,
+// or string. If set returns an error the value from get is shown again and the control gets the
+// ClassPrefix-invalid class. The change isn't reported to OnChange since nothing was stored.
+func Accessor(get func() interface{}, set func(interface{}) error, kind reflect.Kind, title, id, class string,
+	valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return accessorControl(get, set, kind, o)
+}
+
+func accessorControl(get func() interface{}, set func(interface{}) error, kind reflect.Kind,
+	o options) (jquery.JQuery, error) {
+	typ, ok := basicTypes[kind]
+	if !ok {
+		return jq(), fmt.Errorf("kind should be bool, int, float64, or string, got %s instead", kind)
+	}
+	// The control edits val, which is kept the same as the value behind get and set
+	val := reflect.New(typ).Elem()
+	load := func() error {
+		got := get()
+		v := reflect.ValueOf(got)
+		// Numbers convert to strings as runes, which isn't what's wanted
+		if !v.IsValid() || !v.Type().ConvertibleTo(typ) || kind == reflect.String && v.Kind() != reflect.String {
+			return fmt.Errorf("get returned %T, expected %s", got, kind)
+		}
+		val.Set(v.Convert(typ))
+		return nil
+	}
+	if e := load(); e != nil {
+		return jq(), e
+	}
+	var setErr error
+	setter := o.setter
+	// set is only called if the value is actually stored, which a setter from WithSetter might decide against
+	o.setter = func(path string, value interface{}, store func()) {
+		storeAndSet := func() {
+			store()
+			setErr = set(value)
+		}
+		if setter != nil {
+			setter(path, value, storeAndSet)
+		} else {
+			storeAndSet()
+		}
+	}
+	j, e := convert(val, o)
+	if e != nil {
+		return jq(), e
+	}
+	// This runs before any handler added by OnChange, so a failed set can be kept from them
+	j.On(ChangeEvent, func(event jquery.Event) {
+		if event.Namespace != EventNamespace || setErr == nil {
+			return
+		}
+		setErr = nil
+		event.StopImmediatePropagation()
+		if load() == nil {
+			Refresh(j)
+		}
+		j.AddClass(ClassPrefix + "-invalid")
+	})
+	return j, nil
+}
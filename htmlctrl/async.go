@@ -0,0 +1,57 @@
+package htmlctrl
+
+import (
+	"github.com/gopherjs/jquery"
+)
+
+// AsyncValidator is used for checks whose result isn't known right away, such as asking a server whether a
+// username is taken. ValidateAsync is given a value that has already been stored and should eventually send
+// whether it's acceptable on the returned channel. A JS promise can be adapted by sending its result from
+// its then and catch callbacks.
+//
+// While a check is pending the control has the ClassPrefix-pending class. If the value fails it's replaced by the
+// last value that passed and the control gets the ClassPrefix-invalid class. If the user has stored another
+// value in the meantime the result is ignored, since only the latest value matters, and if they've typed
+// something without storing it yet their text is left alone while the stored value is still reverted.
+type AsyncValidator interface {
+	ValidateAsync(interface{}) <-chan bool
+}
+
+// AsyncValidatorFunc is a function that implements AsyncValidator.
+type AsyncValidatorFunc func(interface{}) <-chan bool
+
+// ValidateAsync implements the AsyncValidator interface
+func (v AsyncValidatorFunc) ValidateAsync(i interface{}) <-chan bool {
+	return v(i)
+}
+
+// asyncChecker returns a function to call with each value the control j stores. It checks the value with
+// o.asyncValid, if there is one. restore is called with the last value that passed, starting with initial, when
+// the latest value fails.
+func (o options) asyncChecker(j jquery.JQuery, initial interface{},
+	restore func(confirmed interface{})) func(interface{}) {
+	confirmed, latest := initial, 0
+	return func(v interface{}) {
+		if o.asyncValid == nil {
+			confirmed = v
+			return
+		}
+		latest++
+		check := latest
+		j.AddClass(ClassPrefix + "-pending")
+		result := o.asyncValid.ValidateAsync(v)
+		go func() {
+			ok := <-result
+			if check != latest {
+				return
+			}
+			j.RemoveClass(ClassPrefix + "-pending")
+			if ok {
+				confirmed = v
+				return
+			}
+			j.AddClass(ClassPrefix + "-invalid")
+			restore(confirmed)
+		}()
+	}
+}
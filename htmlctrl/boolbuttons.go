@@ -0,0 +1,49 @@
+package htmlctrl
+
+import (
+	"github.com/gopherjs/jquery"
+)
+
+// BoolButtons takes a pointer to a bool value and returns a JQuery object associated with it in the form of a
+// pair of buttons, labeled with the BoolYes and BoolNo labels. Clicking one stores its value, and the button for
+// the current value has the ClassPrefix-bool-selected class. A non-nil error is returned in the event the
+// conversion fails.
+func BoolButtons(b *bool, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return boolButtonsControl(b, o)
+}
+
+func boolButtonsControl(b *bool, o options) (jquery.JQuery, error) {
+	j := jq("<span>").AddClass(o.typeClass("bool-buttons")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	yes := jqInput("<button>").SetAttr("type", "button").SetText(o.labels.BoolYes)
+	no := jqInput("<button>").SetAttr("type", "button").SetText(o.labels.BoolNo)
+	// show highlights the button for the current value
+	show := func() {
+		yes.ToggleClass(ClassPrefix+"-bool-selected", *b).SetAttr("aria-pressed", *b)
+		no.ToggleClass(ClassPrefix+"-bool-selected", !*b).SetAttr("aria-pressed", !*b)
+	}
+	show()
+	choose := func(btn jquery.JQuery, bNew bool) {
+		btn.Call(jquery.CLICK, func() {
+			if bNew == *b || o.valid != nil && !o.valid.Validate(bNew) {
+				return
+			}
+			old := *b
+			if !o.store(j, bNew, func() {
+				*b = bNew
+			}) {
+				return
+			}
+			show()
+			o.didChange(j, old, bNew)
+		})
+	}
+	choose(yes, true)
+	choose(no, false)
+	onRefresh(j, show)
+	j.Append(yes).Append(no)
+	return j, nil
+}
@@ -0,0 +1,63 @@
+package htmlctrl
+
+import (
+	"fmt"
+
+	"github.com/gopherjs/jquery"
+)
+
+// BoolString takes a pointer to a string that holds a boolean and returns a JQuery object associated with it in
+// the form of an input of checkbox type. A non-nil error is returned in the event the conversion fails, including
+// if the string is neither trueVal nor falseVal. An empty string is set to falseVal. Checking the box stores
+// trueVal and unchecking it stores falseVal. This is for data that can't be changed to an actual bool.
+//
+// valid is given the string that would be stored.
+func BoolString(s *string, trueVal, falseVal, title, id, class string, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.boolVals = []string{trueVal, falseVal}
+	o.valid = valid
+	o.apply(opts)
+	return boolStringControl(s, o)
+}
+
+func boolStringControl(s *string, o options) (jquery.JQuery, error) {
+	trueVal, falseVal := "true", "false"
+	if o.boolVals != nil {
+		trueVal, falseVal = o.boolVals[0], o.boolVals[1]
+	}
+	if trueVal == falseVal {
+		return jq(), fmt.Errorf("true and false values are both '%s'", trueVal)
+	}
+	if *s == "" {
+		*s = falseVal
+	}
+	if *s != trueVal && *s != falseVal {
+		return jq(), fmt.Errorf("value '%s' is neither '%s' nor '%s'", *s, trueVal, falseVal)
+	}
+	j := jqInput("<input>").AddClass(o.typeClass("bool-string")).AddClass(o.class)
+	j.SetAttr("type", "checkbox")
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetProp("checked", *s == trueVal)
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		newS := falseVal
+		if event.Target.Get("checked").Bool() {
+			newS = trueVal
+		}
+		if o.valid != nil && !o.valid.Validate(newS) {
+			j.SetProp("checked", *s == trueVal)
+			return
+		}
+		old := *s
+		if !o.store(j, newS, func() {
+			*s = newS
+		}) {
+			return
+		}
+		o.didChange(j, old, newS)
+	})
+	onRefresh(j, func() {
+		j.SetProp("checked", *s == trueVal)
+	})
+	return j, nil
+}
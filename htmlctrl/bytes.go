@@ -0,0 +1,77 @@
+package htmlctrl
+
+import (
+	"encoding/hex"
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// byteEncoding describes how Bytes shows a byte slice as text.
+type byteEncoding struct {
+	encode func([]byte) string
+	decode func(string) ([]byte, error)
+}
+
+// byteEncodings holds the encodings that Bytes and the encoding tag accept, by name.
+var byteEncodings = map[string]byteEncoding{
+	"hex": {hex.EncodeToString, hex.DecodeString},
+}
+
+// Bytes takes a pointer to a byte slice and returns a JQuery object associated with it in the form of an input
+// of text type holding the bytes in the given encoding. A non-nil error is returned in the event the conversion
+// fails, such as for an unknown encoding. The only encoding is "hex", e.g. "deadbeef". Changes that can't be
+// decoded are reverted.
+//
+// valid, if not nil, is given the decoded bytes.
+func Bytes(slicePtr interface{}, encoding, title, id, class string, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.encoding = encoding
+	o.valid = valid
+	o.apply(opts)
+	return bytesControl(slicePtr, o)
+}
+
+func bytesControl(slicePtr interface{}, o options) (jquery.JQuery, error) {
+	t, v := reflect.TypeOf(slicePtr), reflect.ValueOf(slicePtr)
+	if t.Kind() != reflect.Ptr {
+		return jq(), fmt.Errorf("slicePtr should be a pointer, got %s instead", t.Kind())
+	}
+	if t.Elem().Kind() != reflect.Slice || t.Elem().Elem().Kind() != reflect.Uint8 {
+		return jq(), fmt.Errorf("slicePtr should be a pointer to slice of bytes, got pointer to %s instead", t.Elem())
+	}
+	enc, ok := byteEncodings[o.encoding]
+	if !ok {
+		return jq(), fmt.Errorf("unknown encoding '%s'", o.encoding)
+	}
+	sliceValue := v.Elem()
+
+	j := jqInput("<input>").AddClass(o.typeClass("bytes")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetAttr("type", "text").SetAttr("spellcheck", "false")
+	j.SetAttr("value", enc.encode(sliceValue.Bytes()))
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		b, e := enc.decode(event.Target.Get("value").String())
+		if e != nil || o.valid != nil && !o.valid.Validate(b) {
+			keepFocus(j, func() {
+				j.SetVal(enc.encode(sliceValue.Bytes()))
+			})
+			return
+		}
+		old := copySlice(sliceValue)
+		newSlice := reflect.ValueOf(b).Convert(sliceValue.Type())
+		if !o.store(j, newSlice.Interface(), func() {
+			sliceValue.Set(newSlice)
+		}) {
+			return
+		}
+		j.SetVal(enc.encode(b))
+		o.didChange(j, old, newSlice.Interface())
+	})
+	onRefresh(j, func() {
+		j.SetVal(enc.encode(sliceValue.Bytes()))
+	})
+	return j, nil
+}
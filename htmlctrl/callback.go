@@ -0,0 +1,31 @@
+package htmlctrl
+
+import (
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+var callbackType = reflect.TypeOf((func())(nil))
+
+// callbackControl returns a JQuery object in the form of a button that calls the func fn points to when clicked.
+// It's used for func() fields, which hold callbacks rather than values. The button's text is the name of the
+// field, and it's disabled while the func is nil.
+func callbackControl(fn *func(), o options) (jquery.JQuery, error) {
+	j := jqInput("<button>").AddClass(o.typeClass("callback")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetText(o.label).SetProp("disabled", *fn == nil)
+	j.Call(jquery.CLICK, func() {
+		// Triggering the event programmatically ignores disabled
+		if *fn != nil {
+			(*fn)()
+		}
+	})
+	return j, nil
+}
+
+// isSkipped reports whether Struct leaves out a field of type t. Funcs are only shown if they're callbacks or
+// derived values, since there's nothing else that can be done with them.
+func isSkipped(t reflect.Type) bool {
+	return t.Kind() == reflect.Func && t != callbackType && t != derivedType
+}
@@ -0,0 +1,101 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// EventNamespace is the jQuery namespace of the events that this package triggers, so they can be told apart
+// from the browser's own and removed together.
+const EventNamespace = "gohtmlctrl"
+
+// ChangeEvent is the jQuery event that controls trigger on themselves after storing a new value. It bubbles, so a
+// handler on a container, such as one attached with jQuery's on, is told about the changes of every control within
+// it. The handler is given the path to the value that changed as an extra argument, as for WithRenderHooks ("" for
+// the value a top-level control is bound to), which lets one handler route the changes of many fields or forms.
+//
+// Since the type is change, the handler is also called for the browser's own change events, whose namespace is
+// empty. OnChange ignores those.
+const ChangeEvent = "change." + EventNamespace
+
+// change holds the values for the ChangeEvent currently being handled. jQuery can't carry Go values without
+// converting them to JavaScript, which would turn an int into a float64, so they are kept here instead.
+var change struct {
+	old, new interface{}
+}
+
+// ChangeFunc is called after a control stores a new value. old is the value before the change and new is the
+// value after. Both have the same type as the value the control is bound to, e.g. an int for Int.
+type ChangeFunc func(old, new interface{})
+
+// OnChange arranges for fn to be called whenever the control j, or any control within it, stores a new value.
+// j should have been returned by one of the conversion functions in this package. Changes that are rejected by
+// a validator or by min and max aren't reported since nothing is stored.
+func OnChange(j jquery.JQuery, fn ChangeFunc) {
+	j.On(ChangeEvent, func(event jquery.Event) {
+		if event.Namespace != EventNamespace {
+			return
+		}
+		fn(change.old, change.new)
+	})
+}
+
+// didChange is called by the control j after it stores a new value.
+func (o options) didChange(j jquery.JQuery, old, new interface{}) {
+	if o.changed != nil {
+		o.changed()
+	}
+	// Restore afterwards in case this change was caused by a listener of another one
+	prev := change
+	change.old, change.new = old, new
+	j.Trigger(ChangeEvent, o.path)
+	change = prev
+}
+
+// store makes a write to the bound value by calling write, through the setter if there is one. new is the value
+// being written by the control j. Nothing is written while j is within a root made read-only by SetReadOnly, in
+// which case the controls there are refreshed to show the stored values again and false is returned.
+func (o options) store(j jquery.JQuery, new interface{}, write func()) bool {
+	if root := j.Closest("." + ClassPrefix + "-readonly"); root.Length > 0 {
+		Refresh(root)
+		return false
+	}
+	if o.setter == nil {
+		write()
+		return true
+	}
+	o.setter(o.path, new, write)
+	return true
+}
+
+// copySlice returns a copy of the slice v that doesn't share its backing array.
+func copySlice(v reflect.Value) interface{} {
+	if v.IsNil() {
+		return v.Interface()
+	}
+	cp := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+	reflect.Copy(cp, v)
+	return cp.Interface()
+}
+
+// Mirror returns a JQuery object in the form of a span that shows the value ptr points to and updates whenever the
+// control j, or any control within it, changes. j should be the control that was made from ptr. The text is
+// format applied to the value, or fmt.Sprint if format is nil. This is useful for showing a value elsewhere on the
+// page, such as a summary that follows a form.
+func Mirror(j jquery.JQuery, ptr interface{}, format func(interface{}) string) jquery.JQuery {
+	if format == nil {
+		format = func(v interface{}) string {
+			return fmt.Sprint(v)
+		}
+	}
+	v := reflect.ValueOf(ptr).Elem()
+	mirror := jq("<span>").AddClass(ClassPrefix + "-mirror")
+	mirror.SetText(format(v.Interface()))
+	// Containers report the change of an element, so the whole value is read again rather than using new
+	OnChange(j, func(old, new interface{}) {
+		mirror.SetText(format(v.Interface()))
+	})
+	return mirror
+}
@@ -0,0 +1,17 @@
+package htmlctrl
+
+import (
+	"github.com/gopherjs/jquery"
+)
+
+// Code is the same as String but the input has the ClassPrefix-code class, for showing it in a monospace font,
+// and the browser doesn't spell check, autocapitalize, or autocomplete it. This suits code and identifiers. If
+// multiline is true it's a textarea instead of an input.
+func Code(s *string, title, id, class string, multiline bool, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.widget = "code"
+	o.multiline = multiline
+	o.valid = valid
+	o.apply(opts)
+	return stringControl(s, o)
+}
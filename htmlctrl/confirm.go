@@ -0,0 +1,39 @@
+package htmlctrl
+
+import (
+	"github.com/gopherjs/jquery"
+)
+
+// PasswordConfirm takes a pointer to a string and a pointer to the password it confirms and returns a JQuery
+// object associated with it in the form of an input of password type, plus a message. A non-nil error is returned
+// in the event the conversion fails. Entries that don't match the password are reverted and the message says so.
+// Any validator given by an Option must also pass.
+func PasswordConfirm(s, password *string, title, id, class string, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.apply(opts)
+	return passwordConfirmControl(s, password, o)
+}
+
+func passwordConfirmControl(s, password *string, o options) (jquery.JQuery, error) {
+	j := jq("<span>").AddClass(o.typeClass("confirm")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	msg := jq("<span>").AddClass(ClassPrefix + "-confirm-error")
+	match := ValidateString(func(v string) bool {
+		if v != *password {
+			msg.SetText(o.labels.ConfirmMismatch)
+			return false
+		}
+		msg.SetText("")
+		return true
+	})
+	io := o
+	io.title, io.id, io.class = "", "", ""
+	io.valid = And(match, o.valid)
+	input, e := stringControl(s, io)
+	if e != nil {
+		return jq(), e
+	}
+	input.SetAttr("type", "password")
+	j.Append(input).Append(msg)
+	return j, nil
+}
@@ -0,0 +1,55 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/gopherjs/jquery"
+)
+
+// StringCounter is like String but the input is accompanied by a count of its characters, which updates as the
+// user types. If maxLen is greater than 0 the count is shown out of maxLen, e.g. "12/50", and longer strings are
+// rejected. Strings shorter than minLen are also rejected. While the count is out of range the counter has the
+// ClassPrefix-string-counter-invalid class. The returned JQuery object contains both the input and the counter.
+func StringCounter(s *string, title, id, class string, minLen, maxLen int, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.minLen, o.maxLen = minLen, maxLen
+	o.valid = valid
+	o.apply(opts)
+	return stringCounterControl(s, o)
+}
+
+func stringCounterControl(s *string, o options) (jquery.JQuery, error) {
+	j := jq("<span>").AddClass(o.typeClass("string-counted")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	io := o
+	io.title, io.id, io.class = "", "", ""
+	input, e := stringControl(s, io)
+	if e != nil {
+		return jq(), e
+	}
+	counter := jq("<span>").AddClass(ClassPrefix + "-string-counter")
+	update := func(text string) {
+		count := fmt.Sprint(utf8.RuneCountInString(text))
+		if o.maxLen > 0 {
+			count += fmt.Sprintf("/%d", o.maxLen)
+		}
+		counter.SetText(count)
+		if checkLen(text, o) != nil {
+			counter.AddClass(ClassPrefix + "-string-counter-invalid")
+		} else {
+			counter.RemoveClass(ClassPrefix + "-string-counter-invalid")
+		}
+	}
+	update(*s)
+	input.On("input", func(event jquery.Event) {
+		update(event.Target.Get("value").String())
+	})
+	// A rejected change puts the previous value back without an input event
+	input.Call(jquery.CHANGE, func() {
+		update(*s)
+	})
+	j.Append(input).Append(counter)
+	return j, nil
+}
@@ -0,0 +1,122 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gopherjs/jquery"
+)
+
+// SliceCSV takes a pointer to a slice and returns a JQuery object associated with it in the form of a single
+// input of text type where the elements are separated by commas. A non-nil error is returned in the event the
+// conversion fails. This is simpler than Slice for short lists. The slice's element type must be a string, int,
+// float64, or bool. Spaces around each element are ignored and strings may not contain commas.
+//
+// Each element must pass min, max, and valid as it would for the corresponding control. If any element fails
+// then the whole change is reverted.
+func SliceCSV(slicePtr interface{}, title, id, class string, min, max, step float64, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.min, o.max, o.step = min, max, step
+	o.valid = valid
+	o.apply(opts)
+	return sliceCSVControl(slicePtr, o)
+}
+
+func sliceCSVControl(slicePtr interface{}, o options) (jquery.JQuery, error) {
+	t, v := reflect.TypeOf(slicePtr), reflect.ValueOf(slicePtr)
+	if t.Kind() != reflect.Ptr {
+		return jq(), fmt.Errorf("slicePtr should be a pointer, got %s instead", t.Kind())
+	}
+	if t.Elem().Kind() != reflect.Slice {
+		return jq(), fmt.Errorf("slicePtr should be a pointer to slice, got pointer to %s instead", t.Elem().Kind())
+	}
+	sliceValue := v.Elem()
+	switch kind := t.Elem().Elem().Kind(); kind {
+	case reflect.String, reflect.Int, reflect.Float64, reflect.Bool:
+	default:
+		return jq(), fmt.Errorf("unsupported element type %s for csv", kind)
+	}
+
+	j := jqInput("<input>").AddClass(o.typeClass("slice-csv")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetAttr("type", "text")
+	j.SetAttr("value", formatCSV(sliceValue))
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		newSlice, e := parseCSV(event.Target.Get("value").String(), sliceValue.Type(), o)
+		if e == nil {
+			old := copySlice(sliceValue)
+			if o.store(j, newSlice.Interface(), func() {
+				sliceValue.Set(newSlice)
+			}) {
+				o.didChange(j, old, newSlice.Interface())
+			}
+		}
+		j.SetVal(formatCSV(sliceValue))
+	})
+	onRefresh(j, func() {
+		j.SetVal(formatCSV(sliceValue))
+	})
+	return j, nil
+}
+
+func formatCSV(sliceValue reflect.Value) string {
+	elems := make([]string, sliceValue.Len())
+	for i := range elems {
+		elems[i] = fmt.Sprint(sliceValue.Index(i).Interface())
+	}
+	return strings.Join(elems, ",")
+}
+
+// parseCSV returns a new slice of type sliceType holding the comma separated values in text.
+func parseCSV(text string, sliceType reflect.Type, o options) (reflect.Value, error) {
+	fields := strings.Split(text, ",")
+	if strings.TrimSpace(text) == "" {
+		fields = nil
+	}
+	elemType := sliceType.Elem()
+	newSlice := reflect.MakeSlice(sliceType, 0, len(fields))
+	for i, field := range fields {
+		elem, e := parseValue(strings.TrimSpace(field), elemType.Kind(), o)
+		if e != nil {
+			return newSlice, fmt.Errorf("element %d: %s", i, e)
+		}
+		newSlice = reflect.Append(newSlice, reflect.ValueOf(elem).Convert(elemType))
+	}
+	return newSlice, nil
+}
+
+// parseValue parses text as a value of the given kind and checks that it passes the validator and bounds in o.
+func parseValue(text string, kind reflect.Kind, o options) (interface{}, error) {
+	var val interface{}
+	switch kind {
+	case reflect.String:
+		val = text
+	case reflect.Bool:
+		b, e := strconv.ParseBool(text)
+		if e != nil {
+			return nil, fmt.Errorf("value '%s' has invalid type, expected bool", text)
+		}
+		val = b
+	case reflect.Int:
+		i, e := strconv.Atoi(text)
+		if e != nil {
+			return nil, fmt.Errorf("value '%s' has invalid type, expected an int", text)
+		}
+		val = i
+	case reflect.Float64:
+		f, e := strconv.ParseFloat(text, 64)
+		if e != nil || !isFinite(f) {
+			return nil, fmt.Errorf("value '%s' has invalid type, expected a number", text)
+		}
+		val = f
+	default:
+		return nil, fmt.Errorf("unsupported type %s", kind)
+	}
+	if e := checkValue(reflect.ValueOf(val), o); e != nil {
+		return nil, e
+	}
+	return val, nil
+}
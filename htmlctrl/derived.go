@@ -0,0 +1,26 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+var derivedType = reflect.TypeOf((func() string)(nil))
+
+// derivedControl returns a JQuery object in the form of an output tag showing the result of fn, along with a
+// function that refreshes it. It's used by Struct for func() string fields, whose values are derived from other
+// fields and can't be edited directly.
+func derivedControl(fn func() string, o options) (jquery.JQuery, func(), error) {
+	if fn == nil {
+		return jq(), nil, fmt.Errorf("nil %s", derivedType)
+	}
+	j := jq("<output>").AddClass(o.typeClass("derived")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	refresh := func() {
+		j.SetText(fn())
+	}
+	refresh()
+	return j, refresh, nil
+}
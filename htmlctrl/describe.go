@@ -0,0 +1,277 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// FieldDesc describes what Struct does with one exported field of a struct.
+type FieldDesc struct {
+	// Name is the name of the field
+	Name string
+	// Kind is the kind of the field's type
+	Kind reflect.Kind
+	// Control is the name of the function in this package that creates the field's control, e.g. "ChoiceFilter".
+	// It's "derived" for a derived field, "callback" for a func() field, and "MarshalControl" for a
+	// ControlMarshaler. It's empty if the field isn't rendered.
+	Control string
+	// Rendered is true if the field gets a control
+	Rendered bool
+	// Reason says why the field isn't rendered. This is the same problem that makes Struct return an error, unless
+	// the field is one that Struct leaves out.
+	Reason string
+}
+
+// DescribeStruct takes a pointer to a struct and returns a description of each of its exported fields, as Struct
+// would convert them, without creating any html. It's meant for understanding the effect of a field's tags. A
+// non-nil error is returned if structPtr isn't a pointer to a struct.
+func DescribeStruct(structPtr interface{}) ([]FieldDesc, error) {
+	t, v := reflect.TypeOf(structPtr), reflect.ValueOf(structPtr)
+	if t.Kind() != reflect.Ptr {
+		return nil, fmt.Errorf("structPtr should be a pointer, got %s instead", t.Kind())
+	}
+	if t.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("structPtr should be a pointer to struct, got pointer to %s instead", t.Elem().Kind())
+	}
+	descs, _ := describeFields(t.Elem(), v.Elem(), "")
+	return descs, nil
+}
+
+// describeFields returns the descriptions of the exported fields of the struct structValue, along with the errors
+// that Struct returns for it. path is where the struct is, as in pathError.
+func describeFields(structType reflect.Type, structValue reflect.Value, path string) ([]FieldDesc, fieldErrors) {
+	var descs []FieldDesc
+	var errs fieldErrors
+	for i := 0; i < structType.NumField(); i++ {
+		fieldType := structType.Field(i)
+		if fieldType.PkgPath != "" {
+			continue
+		}
+		desc := FieldDesc{Name: fieldType.Name, Kind: fieldType.Type.Kind()}
+		if isSkipped(fieldType.Type) {
+			desc.Reason = "func fields other than func() and func() string are skipped"
+			descs = append(descs, desc)
+			continue
+		}
+		fieldPath := fieldType.Name
+		if path != "" {
+			fieldPath = path + "." + fieldType.Name
+		}
+		control, e := describeField(structType, fieldType, structValue.Field(i), fieldPath)
+		if e != nil {
+			desc.Reason = e.Error()
+			// Like Struct, leave out a field whose own type isn't supported
+			if !isUnsupported(e) {
+				errs = errs.add(atPath(fieldPath, e))
+			}
+		} else {
+			desc.Control, desc.Rendered = control, true
+		}
+		descs = append(descs, desc)
+	}
+	return descs, errs
+}
+
+// describeField returns the name of the control Struct creates for the field of structType, or the reason it
+// can't.
+func describeField(structType reflect.Type, fieldType reflect.StructField, fieldValue reflect.Value,
+	path string) (string, error) {
+	o, e := parseTag(fieldType.Tag)
+	if e != nil {
+		return "", e
+	}
+	if o.render != "" {
+		renderType := fieldType.Type
+		if renderType.Kind() == reflect.Ptr {
+			renderType = renderType.Elem()
+		}
+		if renderType.Kind() != reflect.Struct {
+			return "", fmt.Errorf("render requires a struct, got %s", fieldType.Type)
+		}
+	}
+	if fieldType.Type == derivedType {
+		if fieldValue.IsNil() {
+			return "", fmt.Errorf("nil %s", derivedType)
+		}
+		deps := fieldType.Tag.Get("derive")
+		if deps == "" {
+			return "", fmt.Errorf("%s requires a derive tag", derivedType)
+		}
+		for _, dep := range strings.Split(deps, ",") {
+			dep = strings.TrimSpace(dep)
+			if _, ok := structType.FieldByName(dep); !ok {
+				return "", fmt.Errorf("derive has unknown field '%s'", dep)
+			}
+		}
+		return "derived", nil
+	}
+	return describeValue(fieldValue, o, path)
+}
+
+// describeValue returns the name of the control convert creates for val, which is at path. It follows the same
+// choices as convert, including for the fields of a struct and the elements of a slice or map.
+func describeValue(val reflect.Value, o options, path string) (string, error) {
+	if val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return "", fmt.Errorf("nil %s", val.Type())
+		}
+		return describeValue(val.Elem(), o, path)
+	}
+	typ := val.Type()
+	if typ.Implements(controlMarshalerType) || reflect.PtrTo(typ).Implements(controlMarshalerType) {
+		return "MarshalControl", nil
+	}
+	// Nil pointers are described by the value they're given once something is stored
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	typ = val.Type()
+	if typ == rawMessageType {
+		return "RawJSON", nil
+	}
+	if typ == timeType {
+		switch o.widget {
+		case "":
+			return "Time", nil
+		case "ago":
+			return "TimeAgo", nil
+		}
+		return "", fmt.Errorf("unknown widget '%s' for time", o.widget)
+	}
+	if typ == callbackType {
+		return "callback", nil
+	}
+	if enum, ok := stringEnums[typ]; ok && o.choices == nil {
+		o.choices = enum
+	}
+	switch kind := typ.Kind(); kind {
+	case reflect.Struct:
+		if _, errs := describeFields(typ, val, path); errs != nil {
+			return "", errs
+		}
+		if o.fieldset {
+			return "StructFieldset", nil
+		}
+		return "Struct", nil
+	case reflect.Map:
+		eo := o.elemOptions()
+		for _, key := range sortedKeys(val) {
+			elemPath := fmt.Sprintf("%s[%v]", path, key.Interface())
+			if _, e := describeValue(val.MapIndex(key), eo, elemPath); e != nil {
+				return "", atPath(elemPath, e)
+			}
+		}
+		return "Map", nil
+	case reflect.Slice:
+		if o.image {
+			if typ.Elem().Kind() != reflect.Uint8 {
+				return "", fmt.Errorf("slicePtr should be a pointer to slice of bytes, got pointer to %s instead", typ)
+			}
+			return "Image", nil
+		}
+		if o.encoding != "" {
+			if typ.Elem().Kind() != reflect.Uint8 {
+				return "", fmt.Errorf("slicePtr should be a pointer to slice of bytes, got pointer to %s instead", typ)
+			}
+			if _, ok := byteEncodings[o.encoding]; !ok {
+				return "", fmt.Errorf("unknown encoding '%s'", o.encoding)
+			}
+			return "Bytes", nil
+		}
+		switch o.widget {
+		case "":
+		case "lines":
+			return "SliceLines", nil
+		case "multiselect":
+			return "MultiSelect", nil
+		case "table", "draggable":
+		default:
+			return "", fmt.Errorf("unknown widget '%s' for slice", o.widget)
+		}
+		if o.csv {
+			return "SliceCSV", nil
+		}
+		eo := o.elemOptions()
+		if o.choices != nil {
+			elemType := typ.Elem()
+			if elemType.Kind() == reflect.Ptr {
+				elemType = elemType.Elem()
+			}
+			if elemType.Kind() != reflect.String {
+				return "", fmt.Errorf("choice requires string elements, got %s", typ.Elem())
+			}
+			eo.choices = o.choices
+		}
+		for i := 0; i < val.Len(); i++ {
+			elem := val.Index(i)
+			// A nil element is shown as a button that gives it a value
+			if elem.Kind() == reflect.Ptr && elem.IsNil() {
+				continue
+			}
+			elemPath := fmt.Sprintf("%s[%d]", path, i)
+			if _, e := describeValue(elem, eo, elemPath); e != nil {
+				return "", atPath(elemPath, e)
+			}
+		}
+		return "Slice", nil
+	case reflect.Bool:
+		switch o.widget {
+		case "":
+			return "Bool", nil
+		case "buttons":
+			return "BoolButtons", nil
+		}
+		return "", fmt.Errorf("unknown widget '%s' for bool", o.widget)
+	case reflect.Int:
+		switch o.widget {
+		case "", "formatted":
+			return "Int", nil
+		case "logrange":
+			return "LogRange", nil
+		}
+		return "", fmt.Errorf("unknown widget '%s' for int", o.widget)
+	case reflect.Float64:
+		switch o.widget {
+		case "", "text", "formatted":
+			return "Float64", nil
+		case "percent":
+			return "Percent", nil
+		case "logrange":
+			return "LogRange", nil
+		}
+		return "", fmt.Errorf("unknown widget '%s' for float64", o.widget)
+	case reflect.String:
+		if o.choices != nil {
+			switch o.widget {
+			case "":
+				return "Choice", nil
+			case "filter":
+				return "ChoiceFilter", nil
+			case "radio":
+				return "Radio", nil
+			}
+			return "", fmt.Errorf("unknown widget '%s' for choice", o.widget)
+		}
+		switch o.widget {
+		case "":
+			if o.counter {
+				return "StringCounter", nil
+			}
+			return "String", nil
+		case "secret":
+			return "Secret", nil
+		case "boolstring":
+			return "BoolString", nil
+		case "code":
+			return "Code", nil
+		case "filter", "radio":
+			return "", fmt.Errorf("widget '%s' requires a choice tag", o.widget)
+		}
+		return "", fmt.Errorf("unknown widget '%s' for string", o.widget)
+	}
+	return "", &unsupportedError{typ.Kind()}
+}
@@ -0,0 +1,102 @@
+package htmlctrl
+
+import (
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// Dirty tracks whether a value has been changed from how it was at some point, such as when it was loaded or last
+// saved. Rather than remembering that a change happened, the value is compared to a copy of how it was, so a
+// value that has been changed back is clean again.
+type Dirty struct {
+	j           jquery.JQuery
+	v, original reflect.Value
+}
+
+// TrackDirty returns a Dirty for the value ptr points to, taking a copy of it as it is now. j should be the control
+// that was made from ptr. Whenever j, or any control within it, changes, j gets the ClassPrefix-dirty class if the
+// value differs from the copy and loses it if it doesn't.
+func TrackDirty(j jquery.JQuery, ptr interface{}) *Dirty {
+	v := reflect.ValueOf(ptr).Elem()
+	d := &Dirty{j, v, deepCopy(v)}
+	// Containers report the change of an element, so the whole value is compared rather than using new
+	OnChange(j, func(old, new interface{}) {
+		j.ToggleClass(ClassPrefix+"-dirty", d.IsDirty())
+	})
+	return d
+}
+
+// IsDirty returns true if the value differs from the copy. Funcs are only the same if they are the same func and
+// nil slices and maps are the same as empty ones, since they're shown the same way.
+func (d *Dirty) IsDirty() bool {
+	return !sameValue(d.v, d.original)
+}
+
+// Reset takes a new copy of the value, such as after it has been saved, so that it's clean, and removes the
+// ClassPrefix-dirty class from the control.
+func (d *Dirty) Reset() {
+	d.original = deepCopy(d.v)
+	d.j.RemoveClass(ClassPrefix + "-dirty")
+}
+
+// sameValue reports whether a and b, which have the same type, hold the same value. Unlike reflect.DeepEqual, funcs
+// that are the same func are the same, nil slices and maps are the same as empty ones, and NaN is the same as
+// itself.
+func sameValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.Ptr:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Pointer() == b.Pointer() || sameValue(a.Elem(), b.Elem())
+	case reflect.Interface:
+		if a.IsNil() || b.IsNil() {
+			return a.IsNil() == b.IsNil()
+		}
+		return a.Elem().Type() == b.Elem().Type() && sameValue(a.Elem(), b.Elem())
+	case reflect.Slice, reflect.Array:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for i := 0; i < a.Len(); i++ {
+			if !sameValue(a.Index(i), b.Index(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Map:
+		if a.Len() != b.Len() {
+			return false
+		}
+		for _, key := range a.MapKeys() {
+			bv := b.MapIndex(key)
+			if !bv.IsValid() || !sameValue(a.MapIndex(key), bv) {
+				return false
+			}
+		}
+		return true
+	case reflect.Struct:
+		for i := 0; i < a.NumField(); i++ {
+			if !sameValue(a.Field(i), b.Field(i)) {
+				return false
+			}
+		}
+		return true
+	case reflect.Bool:
+		return a.Bool() == b.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() == b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() == b.Uint()
+	case reflect.Float32, reflect.Float64:
+		fa, fb := a.Float(), b.Float()
+		return fa == fb || fa != fa && fb != fb
+	case reflect.Complex64, reflect.Complex128:
+		return a.Complex() == b.Complex()
+	case reflect.String:
+		return a.String() == b.String()
+	}
+	// Funcs, chans, and unsafe pointers are the same if they're the same one
+	return a.Pointer() == b.Pointer()
+}
@@ -0,0 +1,41 @@
+package htmlctrl
+
+import (
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// kindAttr returns the html attribute that holds the kind of value a control is for, e.g. "int".
+func kindAttr() string {
+	return "data-" + ClassPrefix + "-type"
+}
+
+// FindByType returns the controls within root, and root itself, that are for values of the given kind, in
+// document order. A pointer's control counts as one for the kind it points to, and an interface's as one for the
+// kind of the value it holds. For example FindByType(form, reflect.Bool) finds every checkbox made for a bool.
+//
+// Only controls made by Value or Struct, Slice, and Map for the values within them are found, since those made
+// directly by functions like Int don't record their kind. The kind is in the data-ClassPrefix-type attribute.
+func FindByType(root jquery.JQuery, kind reflect.Kind) []jquery.JQuery {
+	var found []jquery.JQuery
+	selector := "[" + kindAttr() + "='" + kind.String() + "']"
+	root.Filter(selector).Add(root.Find(selector)).Each(func(i int, elem interface{}) {
+		found = append(found, jq(elem))
+	})
+	return found
+}
+
+// kindOf returns the kind of val, or of what it points to or holds if it's a pointer or interface.
+func kindOf(val reflect.Value) reflect.Kind {
+	for {
+		switch {
+		case val.Kind() == reflect.Ptr && !val.IsNil(), val.Kind() == reflect.Interface && !val.IsNil():
+			val = val.Elem()
+		case val.Kind() == reflect.Ptr:
+			return val.Type().Elem().Kind()
+		default:
+			return val.Kind()
+		}
+	}
+}
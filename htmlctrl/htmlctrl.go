@@ -4,12 +4,16 @@
 package htmlctrl
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
+	"time"
 
+	"github.com/gopherjs/gopherjs/js"
 	"github.com/gopherjs/jquery"
 )
 
@@ -17,30 +21,279 @@ import (
 var ClassPrefix = "go"
 
 var (
-	// SliceAddText is used to fill the add button for a slice
+	// SliceAddText is used to fill the add button for a slice unless other Labels are given
 	SliceAddText = "+"
-	// SliceDelText is used to fill the delete button for a slice
+	// SliceDelText is used to fill the delete button for a slice unless other Labels are given
 	SliceDelText = "-"
+	// DecimalSeparator is what Float64 accepts and shows between the whole and fractional part of a number. If
+	// it isn't "." then inputs are of text type, since number inputs follow the browser's own locale.
+	DecimalSeparator = "."
+	// GroupSeparator is shown between each group of three digits by the formatted widget, e.g. "1,000,000". It's
+	// ignored wherever the user enters it. It should differ from DecimalSeparator.
+	GroupSeparator = ","
+	// SliceCollapseAfter is the number of struct elements a slice can have before they start out collapsed
+	SliceCollapseAfter = 3
 )
 
+// Labels holds all of the text that this package shows to the user, other than what comes from the values
+// themselves. Use it to localize a form without modifying the package variables.
+type Labels struct {
+	// SliceAdd is used to fill the add button for a slice or map
+	SliceAdd string
+	// SliceDel is used to fill the delete button for a slice or map
+	SliceDel string
+	// SecretShow is used to fill the button that reveals a secret
+	SecretShow string
+	// SecretHide is used to fill the button that masks a secret again
+	SecretHide string
+	// SecretCopy is used to fill the button that copies a secret to the clipboard
+	SecretCopy string
+	// StructCopyJSON is used to fill the button that copies a struct to the clipboard as JSON
+	StructCopyJSON string
+	// NilEnable is used to label the checkbox that stands in for a nil pointer
+	NilEnable string
+	// Clear is used to fill the button that clears a string or number
+	Clear string
+	// SliceDup is used to fill the duplicate button for a slice element
+	SliceDup string
+	// ConfirmMismatch is shown when a PasswordConfirm doesn't match its password
+	ConfirmMismatch string
+	// ImageType is shown when the file chosen for an Image isn't a PNG, JPEG, or GIF image
+	ImageType string
+	// ImageInvalid is shown when the image chosen for an Image is rejected by its validator
+	ImageInvalid string
+	// BoolYes and BoolNo are used to fill the buttons of a BoolButtons
+	BoolYes, BoolNo string
+	// TimeNever is shown by a TimeAgo for the zero time
+	TimeNever string
+	// TimeAgo puts into words how long ago d was, e.g. "3 days ago", for a TimeAgo. d is negative for times in the
+	// future. The default is in English. nil means the default.
+	TimeAgo func(d time.Duration) string
+}
+
+// DefaultLabels returns the Labels described by the package variables, e.g. SliceAddText.
+func DefaultLabels() Labels {
+	return Labels{
+		SliceAdd: SliceAddText,
+		SliceDel: SliceDelText,
+		SliceDup: "Duplicate",
+
+		SecretShow: "Show",
+		SecretHide: "Hide",
+		SecretCopy: "Copy",
+
+		StructCopyJSON: "Copy JSON",
+		NilEnable:      "Enable",
+		Clear:          "×",
+
+		ConfirmMismatch: "Doesn't match",
+
+		ImageType:    "not a PNG, JPEG, or GIF image",
+		ImageInvalid: "image is not valid",
+
+		BoolYes: "Yes",
+		BoolNo:  "No",
+
+		TimeNever: "Never",
+		TimeAgo:   formatAgo,
+	}
+}
+
 var jq = jquery.NewJQuery
 
+// ownAttr marks the inputs, selects, textareas, and buttons that this package makes, so that SetReadOnly can leave
+// any of the user's own within a control alone.
+const ownAttr = "data-" + EventNamespace
+
+// jqInput is the same as jq but marks the new element with ownAttr. It's used for every input that's made here.
+func jqInput(html string) jquery.JQuery {
+	return jq(html).SetAttr(ownAttr, "")
+}
+
 // Struct takes a pointer to a struct and returns a JQuery object associated with it. A non-nil error is returned
 // in the event the conversion fails.
 //
-// All exported fields of the struct will recursively converted. Fields that whose types don't support conversion
-// are ignored. A type is supported if it has it's own conversion function in this package.
+// All exported fields of the struct will recursively converted. Fields whose types don't support conversion are
+// left out, unless StrictMode is given, in which case an error listing every such field is returned. A type is
+// supported if it has it's own conversion function in this package. This includes
+// json.RawMessage, which is edited as text rather than as a slice (see RawJSON). Interface fields are converted
+// according to the value they hold, which is set back into the interface when it changes. A pointer to a pointer,
+// such as **int, is followed to the value at the end. Any nil pointers along the way are only allocated once the
+// control stores a value, so showing one doesn't change it. Maps are shown as a list of entries in order of their
+// keys (see Map). Types that implement
+// ControlMarshaler create their own control. A func() field is shown as a button, named after the field, that
+// calls the func when clicked. Other funcs are skipped, apart from the derived fields described below.
 //
 // Struct tags recognized
 //  title - Becomes the "title" html attribute
 //  id - Becomes the "id" html attribute
 //  class - Becomes the "class" html attribute
-//  min - Minimum value for a number
-//  max - Maximum value for a number
+//  tabindex - An int that becomes the "tabindex" html attribute of every input, select, textarea, and button of
+//    the field, including those a slice or map adds later, to change the order that they're reached with the Tab
+//    key.
+//  min - Minimum value for a number, or for a time.Time the earliest time, as a date like "2006-01-02" optionally
+//    followed by a time of day like "T15:04"
+//  max - Maximum value for a number, or for a time.Time the latest time in the same format as min
 //  step - How much the up and down buttons change a number by
-//  choice - Comma separated list. This will created an html choice tag when used on a string type.
-//  valid - Name of a registered validator.
-func Struct(structPtr interface{}, title, id, class string) (jquery.JQuery, error) {
+//  choice - Comma separated list. This will created an html choice tag when used on a string type. On a slice of
+//    strings each element gets a choice tag, unless a widget such as multiselect uses the choices instead.
+//  valid - Name of a registered validator. Without it the validator registered for the field's kind is used, if
+//    any (see RegisterTypeValidator).
+//  widget - Selects an alternative control for the field. One of
+//    filter - Used with choice to create a filterable list instead of a choice tag (see ChoiceFilter).
+//    radio - Used with choice to create radio buttons instead of a choice tag (see Radio).
+//    lines - Used on a slice of strings to create a textarea with one element per line (see SliceLines).
+//    multiselect - Used with choice on a slice of strings to create a select tag that allows multiple
+//      selections (see MultiSelect).
+//    text - Used on a float64 to create an input of text type instead of number type. This lets the user
+//      type in anything ParseFloat accepts, such as "6.022e23", without the browser getting in the way.
+//    secret - Used on a string to mask it, with buttons to show and copy it (see Secret).
+//    code - Used on a string to show it in a monospace font without spell checking or autocapitalization, for
+//      things like code and identifiers (see Code).
+//    boolstring - Used on a string to create a checkbox that stores "true" or "false", or the values given by
+//      the boolvals tag (see BoolString).
+//    percent - Used on a float64 from 0 to 1 to edit it as a percentage with a progress bar (see Percent).
+//    logrange - Used on an int or float64 with 0 < min < max to create a logarithmic slider (see LogRange).
+//    buttons - Used on a bool to create a pair of Yes and No buttons instead of a checkbox (see BoolButtons).
+//    ago - Used on a time.Time to show it as text like "3 days ago" that becomes an input when clicked (see
+//      TimeAgo).
+//    formatted - Used on an int or float64 to show it with GroupSeparator between groups of digits, e.g.
+//      "1,000,000", while it doesn't have focus. The separators are taken out while it's being edited.
+//    table - Used on a slice of structs, the same as the table tag.
+//    draggable - Used on a slice to let its elements be reordered by dragging and dropping them (see
+//      WithDraggable).
+//  unit - Text shown after an int or float64, such as "MB" or "seconds" (see WithUnit).
+//  prefix - Text shown before an int or float64, such as "$" (see WithUnit).
+//  multiline - If "true" a string is edited in a textarea instead of an input.
+//  autocomplete - Becomes the "autocomplete" html attribute of a string or number input, e.g. "off" or "email"
+//    (see WithAutocomplete).
+//  style - Only "buttons", which is the same as widget:"buttons" on a bool.
+//  boolvals - The two comma separated values that a boolstring stores when checked and unchecked, e.g. "yes,no".
+//  clear - If "true" a string, int, float64, or choice is followed by a button that sets it to the empty string,
+//    0, or the first choice. A pointer to an int or float64 is set to nil instead.
+//  throttle - A duration, such as "100ms". Numbers will update while they are being changed instead of only
+//    when the change is complete, but no more than once per duration.
+//  shiftstep - A number, such as "10". Pressing the up or down arrow key while holding Shift changes a number by
+//    this many steps instead of one.
+//  positive - If "true" an int or float64 must be greater than 0. This applies regardless of min.
+//  nonnegative - If "true" an int or float64 must be 0 or more. This applies regardless of min.
+//  decimals - The most digits a float64 may have after the decimal point, e.g. "2" for an amount of money.
+//    Entries with more are rejected. Trailing zeros aren't counted.
+//  keyfilter - If "true" numbers only let the user type characters that can be part of a number. Pasting isn't
+//    affected.
+//  commit - One of "change", "blur", or "input", for when a string or number stores what the user enters (see
+//    WithCommitMode). It applies to everything within the field.
+//  enter - Either "true" or "next". Numbers will update when Enter is pressed instead of waiting for the input to
+//    lose focus. With "next" the next control also gets focus, for quick keyboard entry.
+//  csv - If "true" a slice is shown as a single text input of comma separated values (see SliceCSV).
+//  encoding - Used on a byte slice to edit it as text in the given encoding, which must be "hex" (see Bytes).
+//  image - If "true" a byte slice holding a PNG, JPEG, or GIF image is shown as a preview with a file input to
+//    replace it (see Image).
+//  heading - Text for a heading that is placed before the field, to break a long struct into sections.
+//  fieldset - If "true" a struct is shown as a fieldset tag with its title, or else the field name, as the
+//    legend.
+//  inline - If "true" a struct's fields are laid out in a row instead of stacked, with the field names as short
+//    labels. The struct gets the ClassPrefix-struct-inline class and its fields ClassPrefix-struct-inline-field.
+//  render - Used on a field that is a struct or a pointer to one to change how it's placed within its parent. One of
+//    inline - The struct's fields are placed among the parent's own, as if they were promoted from it, instead
+//      of under the field's name.
+//    section - The struct is placed in a details tag with the ClassPrefix-struct-section class and the field's
+//      name as the summary, so it can be collapsed. It starts out open.
+//  slicevalid - Name of a registered validator that is given the whole slice whenever an element is added,
+//    deleted, or changed. If it returns false the change is reverted. This allows rules such as uniqueness.
+//  addvalid - If "true" the add button of a slice is disabled while any element fails valid, min, or max. This
+//    keeps the user from adding more elements before filling in the new one.
+//  table - If "true" a slice of structs is shown as a table with a header row of field names and a row of controls
+//    for each element. Clicking a field name sorts the slice by that field, and clicking it again reverses the
+//    order.
+//  ordered - If "true" a slice is shown as a numbered list, i.e. an ol tag instead of a ul tag.
+//  addtext - Text for the add button of a slice or map, instead of the one from its labels.
+//  deltext - Text for the delete buttons of a slice or map, instead of the one from its labels.
+//  emptytext - Text that is shown in place of the elements of a slice while it's empty (see WithEmptyText).
+//  duplicate - If "true" each element of a slice has a button that appends a deep copy of it to the slice.
+//    Pointer elements are copied along with what they point to, so the copy can be changed independently.
+//  minlen - Minimum number of characters in a string.
+//  maxlen - Maximum number of characters in a string. This also becomes the "maxlength" html attribute.
+//  counter - If "true" a string is shown with a count of its characters that updates as the user types (see
+//    StringCounter).
+//  copyjson - If "true" a struct is followed by a button that copies it to the clipboard as JSON (see
+//    StructCopyJSON).
+//  nonative - If "true" the html attributes that the browser validates on its own, such as min, max, and
+//    maxlength, are left out of everything within the field. The limits are still enforced the same way as
+//    usual, this only avoids the browser showing its own messages as well.
+//  soft - If "true" invalid entries into an int, float64, string, or json.RawMessage are left in place rather than
+//    reverted. The value isn't stored until it's valid, and meanwhile the input has the ClassPrefix-invalid class
+//    (see FormValid).
+//  derive - Comma separated names of the fields that a func() string field depends on. The field is shown
+//    read-only as the result of the func, which is called again whenever one of those fields changes.
+func Struct(structPtr interface{}, title, id, class string, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.apply(opts)
+	return structControl(structPtr, o)
+}
+
+// StructFieldset is the same as Struct but the returned JQuery object is a fieldset tag with title as its legend.
+func StructFieldset(structPtr interface{}, title, id, class string, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.fieldset = true
+	o.apply(opts)
+	return structControl(structPtr, o)
+}
+
+// StructLabels is the same as Struct but everything within it uses the given labels.
+func StructLabels(structPtr interface{}, title, id, class string, labels Labels,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.labels = labels
+	o.apply(opts)
+	return structControl(structPtr, o)
+}
+
+// StructFields is the same as Struct but only the named fields are converted, in the given order. This allows a
+// struct to be split across several forms, such as the pages of a wizard, that all change the same value. A
+// non-nil error is returned if a name isn't that of an exported field.
+func StructFields(structPtr interface{}, fieldNames []string, title, id, class string,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.fields = fieldNames
+	o.apply(opts)
+	return structControl(structPtr, o)
+}
+
+// StructCopyJSON is the same as Struct but it includes a button that copies the struct to the clipboard as JSON,
+// which is handy for debugging or sharing. If the struct can't be marshaled, or the clipboard isn't available,
+// the error is shown next to the button.
+func StructCopyJSON(structPtr interface{}, title, id, class string, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.copyJSON = true
+	o.apply(opts)
+	return structControl(structPtr, o)
+}
+
+// StructAutoID is the same as Struct but everything within it that doesn't have an id tag is given an id made
+// from id and the path to it, e.g. "form-Items-0-Name". The labels of the fields refer to these ids. A non-nil
+// error is returned if id is empty.
+func StructAutoID(structPtr interface{}, title, id, class string, opts ...Option) (jquery.JQuery, error) {
+	if id == "" {
+		return jq(), fmt.Errorf("id is required to generate ids from")
+	}
+	o := attrOptions(title, id, class)
+	o.autoID = true
+	o.apply(opts)
+	return structControl(structPtr, o)
+}
+
+// StructWithActions is the same as Struct but it includes a button for each exported method of structPtr that
+// is a func() or func() error, labeled with the method's name. Clicking one calls the method and then rebuilds
+// the controls, so they show any changes it made. An error returned by the method is shown next to the buttons.
+func StructWithActions(structPtr interface{}, title, id, class string, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.actions = true
+	o.apply(opts)
+	return structControl(structPtr, o)
+}
+
+func structControl(structPtr interface{}, o options) (jquery.JQuery, error) {
 	t, v := reflect.TypeOf(structPtr), reflect.ValueOf(structPtr)
 	if t.Kind() != reflect.Ptr {
 		return jq(), fmt.Errorf("structPtr should be a pointer, got %s instead", t.Kind())
@@ -50,63 +303,287 @@ func Struct(structPtr interface{}, title, id, class string) (jquery.JQuery, erro
 	}
 	structType, structValue := t.Elem(), v.Elem()
 
-	j := jq("<div>").AddClass(ClassPrefix + "-struct").AddClass(class)
-	j.SetAttr("title", title).SetAttr("id", id)
-	for i := 0; i < structType.NumField(); i++ {
-		fieldType := structType.Field(i)
-		// Ignore unexported fields
-		if fieldType.PkgPath != "" {
-			continue
+	j := jq("<div>")
+	if o.cells {
+		j = jq("<tr>")
+	} else if o.fieldset {
+		j = jq("<fieldset>")
+		legend := o.title
+		if legend == "" {
+			legend = o.label
 		}
-		fieldValue := structValue.Field(i)
-		tag := fieldType.Tag
-		validName := tag.Get("valid")
-		valid, ok := validators[validName]
-		if validName != "" && !ok {
-			return jq(), fmt.Errorf("unregistered validator '%s'", validName)
+		if legend != "" {
+			j.Append(jq("<legend>").SetText(legend))
 		}
-		min, e := strconv.ParseFloat(tag.Get("min"), 64)
-		if e != nil {
-			if tag.Get("min") != "" {
-				return jq(), fmt.Errorf("min as value '%s' expected a number", tag.Get("min"))
-			}
-			min = math.NaN()
+	}
+	j.AddClass(o.typeClass("struct")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	if o.inline {
+		j.AddClass(ClassPrefix + "-struct-inline")
+	}
+	// derived holds the refresh functions of derived fields by the names of the fields they depend on
+	derived := map[string][]func(){}
+	// errs holds the errors of every field that can't be converted, so they can all be fixed at once
+	var errs fieldErrors
+	var fields []reflect.StructField
+	if o.fields == nil {
+		for i := 0; i < structType.NumField(); i++ {
+			fields = append(fields, structType.Field(i))
 		}
-		max, e := strconv.ParseFloat(tag.Get("max"), 64)
-		if e != nil {
-			if tag.Get("max") != "" {
-				return jq(), fmt.Errorf("max as value '%s' expected a number", tag.Get("max"))
+	} else {
+		for _, name := range o.fields {
+			field, ok := structType.FieldByName(name)
+			if !ok || len(field.Index) != 1 || field.PkgPath != "" {
+				return jq(), fmt.Errorf("no exported field named '%s'", name)
 			}
-			max = math.NaN()
+			fields = append(fields, field)
+		}
+	}
+	for _, fieldType := range fields {
+		// Ignore unexported fields and funcs that can't be shown
+		if fieldType.PkgPath != "" || isSkipped(fieldType.Type) {
+			continue
 		}
-		step, e := strconv.ParseFloat(tag.Get("step"), 64)
+		fieldValue := structValue.Field(fieldType.Index[0])
+		path := fieldType.Name
+		if o.path != "" {
+			path = o.path + "." + fieldType.Name
+		}
+		fo, e := o.tagOptions(fieldType.Tag)
 		if e != nil {
-			if tag.Get("step") != "" {
-				return jq(), fmt.Errorf("step as value '%s' expected a number", tag.Get("step"))
+			errs = errs.add(atPath(path, e))
+			continue
+		}
+		fo.inherit(o)
+		fo.label = fieldType.Name
+		fo.path = path
+		if fo.render != "" {
+			structType := fieldType.Type
+			if structType.Kind() == reflect.Ptr {
+				structType = structType.Elem()
+			}
+			if structType.Kind() != reflect.Struct {
+				errs = errs.add(atPath(path, fmt.Errorf("render requires a struct, got %s", fieldType.Type)))
+				continue
 			}
-			step = math.NaN()
+		}
+		if o.autoID && fo.id == "" {
+			fo.id = o.id + "-" + fieldType.Name
 		}
 
-		field, e := convert(fieldValue, tag.Get("title"), tag.Get("id"), tag.Get("class"), tag.Get("choice"),
-			min, max, step, valid)
+		var field jquery.JQuery
+		if fieldType.Type == derivedType {
+			var refresh func()
+			field, refresh, e = derivedControl(fieldValue.Interface().(func() string), fo)
+			if e != nil {
+				errs = errs.add(atPath(path, e))
+				continue
+			}
+			deps := fieldType.Tag.Get("derive")
+			if deps == "" {
+				errs = errs.add(atPath(path, fmt.Errorf("%s requires a derive tag", derivedType)))
+				continue
+			}
+			unknown := false
+			for _, dep := range strings.Split(deps, ",") {
+				dep = strings.TrimSpace(dep)
+				if _, ok := structType.FieldByName(dep); !ok {
+					errs = errs.add(atPath(path, fmt.Errorf("derive has unknown field '%s'", dep)))
+					unknown = true
+					continue
+				}
+				derived[dep] = append(derived[dep], refresh)
+			}
+			if unknown {
+				continue
+			}
+		} else {
+			name := fieldType.Name
+			fo.changed = func() {
+				for _, refresh := range derived[name] {
+					refresh()
+				}
+				if o.changed != nil {
+					o.changed()
+				}
+			}
+			field, e = render(fieldValue, fo)
+			if e, ok := e.(*pathError); ok && !o.strict && e.path == path && isUnsupported(e.err) {
+				continue
+			}
+		}
 		if e != nil {
-			return jq(), fmt.Errorf("converting struct field %s (%s): %s", fieldType.Name, fieldType.Type.Kind(), e)
+			errs = errs.add(atPath(path, e))
+			continue
+		}
+		if o.cells {
+			j.Append(jq("<td>").Append(field))
+			continue
+		}
+		if heading := fieldType.Tag.Get("heading"); heading != "" {
+			j.Append(jq("<h4>").AddClass(ClassPrefix + "-struct-heading").SetText(heading))
+		}
+		switch fo.render {
+		case "inline":
+			j.Append(field.Contents())
+			continue
+		case "section":
+			summary := jq("<summary>").SetText(fieldType.Name)
+			section := jq("<details>").AddClass(ClassPrefix+"-struct-section").SetProp("open", true)
+			j.Append(section.Append(summary).Append(field))
+			continue
 		}
 		jf := jq("<div>").AddClass(ClassPrefix + "-struct-field")
-		jf.Append(jq("<label>").SetText(fieldType.Name))
+		if o.inline {
+			jf = jq("<span>").AddClass(ClassPrefix + "-struct-inline-field")
+		}
+		label := jq("<label>").SetText(fieldType.Name)
+		if fo.id != "" {
+			label.SetAttr("for", fo.id)
+		}
+		jf.Append(label)
 		jf.Append(field)
 		j.Append(jf)
 	}
+	if len(errs) == 1 {
+		return jq(), errs[0]
+	} else if len(errs) > 1 {
+		return jq(), errs
+	}
+	if o.copyJSON {
+		j.Append(copyJSONButton(structPtr, o))
+	}
+	if o.actions {
+		j.Append(actionButtons(structPtr, j, o))
+	}
+	if o.ready != nil {
+		o.ready(j)
+	}
 	return j, nil
 }
 
-// Slice takes a pointer to a slice and returns a JQuery object associated with it as a list tag. A non-nil error
-// is returned in the event the conversion fails. It includes buttons for adding and removing elements from the
-// slice. The slice's type must be among those supported by this package or a pointer to one. An error will be
-// returned if the slice's type is not supported.
+var errorType = reflect.TypeOf((*error)(nil)).Elem()
+
+// actionButtons returns a button for each method of structPtr that takes nothing and returns nothing or an
+// error. After a method is called the contents of j, the struct's control, are rebuilt.
+func actionButtons(structPtr interface{}, j jquery.JQuery, o options) jquery.JQuery {
+	actions := jq("<div>").AddClass(ClassPrefix + "-struct-actions")
+	errText := jq("<span>").AddClass(ClassPrefix + "-struct-action-error")
+	v := reflect.ValueOf(structPtr)
+	for i := 0; i < v.NumMethod(); i++ {
+		method, methodType := v.Method(i), v.Type().Method(i)
+		mt := method.Type()
+		if mt.NumIn() != 0 || mt.NumOut() > 1 || mt.NumOut() == 1 && mt.Out(0) != errorType {
+			continue
+		}
+		btn := jqInput("<button>").AddClass(ClassPrefix + "-struct-action").SetText(methodType.Name)
+		btn.Call(jquery.CLICK, func() {
+			errText.SetText("")
+			out := method.Call(nil)
+			if len(out) == 1 && !out[0].IsNil() {
+				errText.SetText(out[0].Interface().(error).Error())
+			}
+			fo := o
+			fo.actions, fo.ready = false, nil
+			fresh, e := structControl(structPtr, fo)
+			if e != nil {
+				errText.SetText(e.Error())
+				return
+			}
+			actions.Detach()
+			j.Empty().Append(fresh.Contents()).Append(actions)
+			setTabIndex(j, o)
+			if o.ready != nil {
+				o.ready(j)
+			}
+			if o.changed != nil {
+				o.changed()
+			}
+		})
+		actions.Append(btn)
+	}
+	return actions.Append(errText)
+}
+
+// copyJSONButton returns a button that copies v to the clipboard as JSON, along with a place to show errors.
+func copyJSONButton(v interface{}, o options) jquery.JQuery {
+	errText := jq("<span>").AddClass(ClassPrefix + "-struct-copy-error")
+	btn := jqInput("<button>").AddClass(ClassPrefix + "-struct-copy").SetText(o.labels.StructCopyJSON)
+	btn.Call(jquery.CLICK, func() {
+		errText.SetText("")
+		b, e := json.MarshalIndent(v, "", "  ")
+		if e != nil {
+			errText.SetText(e.Error())
+			return
+		}
+		// The clipboard API is only available in secure contexts
+		clipboard := js.Global.Get("navigator").Get("clipboard")
+		if clipboard == js.Undefined {
+			errText.SetText("clipboard is not available")
+			return
+		}
+		clipboard.Call("writeText", string(b)).Call("catch", func(err *js.Object) {
+			errText.SetText(err.String())
+		})
+	})
+	return jq("<span>").Append(btn).Append(errText)
+}
+
+// Slice takes a pointer to a slice and returns a JQuery object associated with it as a ul tag, or an ol tag with
+// WithOrdered. A non-nil error is returned in the event the conversion fails. It includes buttons for adding and
+// removing elements from the slice, with the add button in a list item of its own at the end. The slice's type
+// must be among those supported by this package or a pointer to one. An error will be returned if the slice's
+// type is not supported.
+//
+// Elements that are structs are placed in a details tag so they can be collapsed. They start out collapsed if
+// there are more than SliceCollapseAfter elements. With WithTable they're shown as the rows of a table instead,
+// which can be sorted by clicking the name of a column. With WithDraggable the elements can be reordered by
+// dragging one onto another.
+// Elements that are nil pointers are shown as a checkbox that allocates the element when checked.
+//
+// Added elements start out as the zero value, or as a copy of the prototype given with WithPrototype.
+//
+// Elements that are interfaces, such as those of a []interface{}, are each shown according to the value they hold.
+// Since the zero value of an interface is nil there is only an add button if there's a prototype, which decides
+// the type of each new element.
 //
 // min, max, step, and valid will be applied if the slices element type supports it.
-func Slice(slicePtr interface{}, title, id, class string, min, max, step float64, valid Validator) (jquery.JQuery, error) {
+func Slice(slicePtr interface{}, title, id, class string, min, max, step float64, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.min, o.max, o.step = min, max, step
+	o.valid = valid
+	o.apply(opts)
+	return sliceControl(slicePtr, o)
+}
+
+// SliceLabels is the same as Slice but everything within it uses the given labels.
+func SliceLabels(slicePtr interface{}, title, id, class string, min, max, step float64, valid Validator,
+	labels Labels, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.min, o.max, o.step = min, max, step
+	o.valid = valid
+	o.labels = labels
+	o.apply(opts)
+	return sliceControl(slicePtr, o)
+}
+
+// SliceAutoID is the same as Slice but everything within it is given an id made from id and the path to it, e.g.
+// "list-0" for the first element. A non-nil error is returned if id is empty.
+func SliceAutoID(slicePtr interface{}, title, id, class string, min, max, step float64,
+	valid Validator, opts ...Option) (jquery.JQuery, error) {
+	if id == "" {
+		return jq(), fmt.Errorf("id is required to generate ids from")
+	}
+	o := attrOptions(title, id, class)
+	o.min, o.max, o.step = min, max, step
+	o.valid = valid
+	o.autoID = true
+	o.apply(opts)
+	return sliceControl(slicePtr, o)
+}
+
+func sliceControl(slicePtr interface{}, o options) (jquery.JQuery, error) {
 	t, v := reflect.TypeOf(slicePtr), reflect.ValueOf(slicePtr)
 	if t.Kind() != reflect.Ptr {
 		return jq(), fmt.Errorf("slicePtr should be a pointer, got %s instead", t.Kind())
@@ -116,57 +593,326 @@ func Slice(slicePtr interface{}, title, id, class string, min, max, step float64
 	}
 	sliceType, sliceValue := t.Elem(), v.Elem()
 	sliceElemType := sliceType.Elem()
+	var proto reflect.Value
+	if o.prototype != nil {
+		proto = reflect.ValueOf(o.prototype)
+		baseType := sliceElemType
+		if baseType.Kind() == reflect.Ptr {
+			baseType = baseType.Elem()
+		}
+		if sliceElemType.Kind() == reflect.Interface {
+			if !proto.Type().AssignableTo(sliceElemType) {
+				return jq(), fmt.Errorf("prototype of type %s doesn't implement element type %s", proto.Type(),
+					sliceElemType)
+			}
+		} else if proto.Type() != sliceElemType && proto.Type() != baseType {
+			return jq(), fmt.Errorf("prototype of type %s doesn't match element type %s", proto.Type(), sliceElemType)
+		} else if proto.Kind() == reflect.Ptr {
+			proto = proto.Elem()
+		}
+	}
+
+	var columns []string
+	if o.table {
+		structType := sliceElemType
+		if structType.Kind() == reflect.Ptr {
+			structType = structType.Elem()
+		}
+		if structType.Kind() != reflect.Struct {
+			return jq(), fmt.Errorf("table requires struct elements, got %s", sliceElemType)
+		}
+		for i := 0; i < structType.NumField(); i++ {
+			if field := structType.Field(i); field.PkgPath == "" {
+				columns = append(columns, field.Name)
+			}
+		}
+	}
 
-	j := jq("<list>").AddClass(ClassPrefix + "-slice").AddClass(class)
-	j.SetAttr("title", title).SetAttr("id", id)
+	j := jq("<ul>")
+	if o.table {
+		j = jq("<table>").AddClass(o.typeClass("slice-table"))
+	} else if o.ordered {
+		j = jq("<ol>")
+	}
+	j.AddClass(o.typeClass("slice")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	if sliceElemType.Kind() == reflect.Ptr {
+		// Elements may point to the same value, so a change to one can affect the others
+		OnChange(j, func(old, new interface{}) {
+			Refresh(j)
+		})
+	}
 
+	eo := o.elemOptions()
+	if o.choices != nil {
+		// Only widgets like multiselect use the choices for the slice as a whole, otherwise they're for each element
+		elemType := sliceElemType
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if elemType.Kind() != reflect.String {
+			return jq(), fmt.Errorf("choice requires string elements, got %s", sliceElemType)
+		}
+		eo.choices = o.choices
+	}
+	var addBtn jquery.JQuery
+	// updateAddBtn disables the add button while any element is invalid, if that was asked for
+	updateAddBtn := func() {
+		if !o.addValid {
+			return
+		}
+		invalid := false
+		for i := 0; i < sliceValue.Len(); i++ {
+			if checkValue(sliceValue.Index(i), eo) != nil {
+				invalid = true
+			}
+		}
+		addBtn.SetProp("disabled", invalid)
+	}
+	// snapshot is a copy of the slice as of the last change that sliceValid accepted
+	var snapshot reflect.Value
+	if o.sliceValid != nil {
+		snapshot = deepCopy(sliceValue)
+	}
 	var populate func() error
+	// rebuild redoes every element. This is simpler than working out which ones are affected when the slice
+	// resizes, since non-pointer elements are bound to their index.
+	rebuild := func() {
+		j.Empty()
+		if e := populate(); e != nil {
+			panic(e)
+		}
+		setTabIndex(j, o)
+	}
+	eo.changed = func() {
+		if o.sliceValid != nil {
+			if !o.sliceValid.Validate(sliceValue.Interface()) {
+				o.store(j, snapshot.Interface(), func() {
+					restoreSlice(sliceValue, snapshot)
+				})
+				rebuild()
+				return
+			}
+			snapshot = deepCopy(sliceValue)
+		}
+		updateAddBtn()
+		if o.changed != nil {
+			o.changed()
+		}
+	}
+	// commit stores newSlice, which shouldn't share a backing array with the slice, unless sliceValid rejects it
+	commit := func(newSlice reflect.Value) {
+		if o.sliceValid != nil && !o.sliceValid.Validate(newSlice.Interface()) {
+			return
+		}
+		old := copySlice(sliceValue)
+		if !o.store(j, newSlice.Interface(), func() {
+			sliceValue.Set(newSlice)
+		}) {
+			return
+		}
+		// Only now that it's stored can the slice be restored to it
+		if o.sliceValid != nil {
+			snapshot = deepCopy(newSlice)
+		}
+		o.didChange(j, old, sliceValue.Interface())
+		rebuild()
+	}
+	// dragged is the index of the element being dragged, or -1 if there isn't one
+	dragged := -1
+	// moveElem moves the element at index from to index to, shifting the ones in between
+	moveElem := func(from, to int) {
+		elem := sliceValue.Index(from)
+		newSlice := reflect.MakeSlice(sliceType, 0, sliceValue.Len())
+		for k := 0; k < sliceValue.Len(); k++ {
+			if k == from {
+				continue
+			}
+			if k == to && from > to {
+				newSlice = reflect.Append(newSlice, elem)
+			}
+			newSlice = reflect.Append(newSlice, sliceValue.Index(k))
+			if k == to && from < to {
+				newSlice = reflect.Append(newSlice, elem)
+			}
+		}
+		commit(newSlice)
+	}
+	// sortBy and sortDesc are the column that the table was last sorted by and whether it was in reverse
+	var sortBy string
+	var sortDesc bool
+	// sortRows sorts the elements by the field name, reversing the order if they're already sorted by it. Nil
+	// elements go last.
+	sortRows := func(name string) {
+		sortDesc = name == sortBy && !sortDesc
+		sortBy = name
+		field := func(elem reflect.Value) reflect.Value {
+			if elem.Kind() == reflect.Ptr {
+				if elem.IsNil() {
+					return reflect.Value{}
+				}
+				elem = elem.Elem()
+			}
+			return elem.FieldByName(name)
+		}
+		elems := make([]reflect.Value, sliceValue.Len())
+		for i := range elems {
+			elems[i] = sliceValue.Index(i)
+		}
+		sort.SliceStable(elems, func(a, b int) bool {
+			fa, fb := field(elems[a]), field(elems[b])
+			if !fa.IsValid() || !fb.IsValid() {
+				return fa.IsValid()
+			}
+			if sortDesc {
+				return lessValue(fb, fa)
+			}
+			return lessValue(fa, fb)
+		})
+		newSlice := reflect.MakeSlice(sliceType, 0, len(elems))
+		commit(reflect.Append(newSlice, elems...))
+	}
+
+	// newElem returns a value for a new element, which is a pointer if the elements are
+	newElem := func() reflect.Value {
+		var elem reflect.Value
+		if sliceElemType.Kind() == reflect.Interface {
+			elem = reflect.New(sliceElemType).Elem()
+			elem.Set(deepCopy(proto))
+			return elem
+		}
+		if sliceElemType.Kind() == reflect.Ptr {
+			elem = reflect.New(sliceElemType.Elem())
+		} else {
+			elem = reflect.New(sliceElemType)
+		}
+		if proto.IsValid() {
+			elem.Elem().Set(deepCopy(proto))
+		}
+		if sliceElemType.Kind() == reflect.Ptr {
+			return elem
+		}
+		return elem.Elem()
+	}
+
 	populate = func() error {
-		newLi := func(j, ji jquery.JQuery) jquery.JQuery {
+		// newLi returns the list item for element i. The list is rebuilt whenever the slice changes length so i
+		// stays correct regardless of what else is in the list.
+		newLi := func(j, ji jquery.JQuery, i int) jquery.JQuery {
 			li := jq("<li>").Append(ji)
-			delBtn := jq("<button>").SetText(SliceDelText)
+			if o.table {
+				// ji is already a row so the buttons get a cell of their own
+				li = jq("<td>")
+			}
+			delBtn := jqInput("<button>").SetText(o.sliceText().SliceDel)
 			delBtn.Call(jquery.CLICK, func() {
-				i := li.Call("index").Get().Int()
-				li.Remove()
-				begin := sliceValue.Slice(0, i)
-				end := sliceValue.Slice(i+1, sliceValue.Len())
-				sliceValue.Set(reflect.AppendSlice(begin, end))
-				// Just delete and redo everything to work with non-pointers when the slice resizes
-				j.Empty()
-				e := populate()
-				if e != nil {
-					panic(e)
-				}
+				newSlice := reflect.MakeSlice(sliceType, 0, sliceValue.Len()-1)
+				newSlice = reflect.AppendSlice(newSlice, sliceValue.Slice(0, i))
+				newSlice = reflect.AppendSlice(newSlice, sliceValue.Slice(i+1, sliceValue.Len()))
+				commit(newSlice)
 			})
 			li.Append(delBtn)
-			return li
+			if o.duplicate {
+				dupBtn := jqInput("<button>").SetText(o.labels.SliceDup)
+				dupBtn.Call(jquery.CLICK, func() {
+					newSlice := reflect.ValueOf(copySlice(sliceValue))
+					commit(reflect.Append(newSlice, deepCopy(sliceValue.Index(i))))
+				})
+				li.Append(dupBtn)
+			}
+			item := li
+			if o.table {
+				item = ji.Append(li)
+			}
+			if o.draggable {
+				onDrag(item, i, &dragged, moveElem)
+			}
+			return item
+		}
+		// fullRow returns content in a row of the table that spans all of the columns
+		fullRow := func(content jquery.JQuery) jquery.JQuery {
+			return jq("<tr>").Append(jq("<td>").SetAttr("colspan", len(columns)+1).Append(content))
 		}
 
+		if o.table {
+			header := jq("<tr>")
+			for _, name := range columns {
+				name := name
+				th := jq("<th>").AddClass(ClassPrefix + "-slice-sort")
+				if name == sortBy && sortDesc {
+					th.AddClass(ClassPrefix + "-slice-sort-desc")
+				} else if name == sortBy {
+					th.AddClass(ClassPrefix + "-slice-sort-asc")
+				}
+				th.SetText(name).Call(jquery.CLICK, func() {
+					sortRows(name)
+				})
+				header.Append(th)
+			}
+			j.Append(jq("<thead>").Append(header.Append(jq("<th>"))))
+		}
 		for i := 0; i < sliceValue.Len(); i++ {
 			elem := sliceValue.Index(i)
-			ji, e := convert(elem, "", "", "", "", min, max, step, valid)
+			if elem.Kind() == reflect.Ptr && elem.IsNil() {
+				i := i
+				toggle := nilToggle(o, func() {
+					newSlice := reflect.ValueOf(copySlice(sliceValue))
+					newSlice.Index(i).Set(newElem())
+					commit(newSlice)
+				})
+				if o.table {
+					toggle = fullRow(toggle)
+				}
+				j.Append(newLi(j, toggle, i))
+				continue
+			}
+			ei := eo
+			if o.autoID {
+				ei.id = fmt.Sprintf("%s-%d", o.id, i)
+			}
+			ei.cells = o.table
+			ei.path = fmt.Sprintf("%s[%d]", o.path, i)
+			ji, e := render(elem, ei)
 			if e != nil {
-				return fmt.Errorf("converting slice element %d (%s): %s", i, elem.Type().Kind(), e)
+				return e
 			}
-			j.Append(newLi(j, ji))
+			isStruct := elem.Kind() == reflect.Struct ||
+				elem.Kind() == reflect.Ptr && elem.Type().Elem().Kind() == reflect.Struct
+			if isStruct && !o.table {
+				summary := jq("<summary>").SetText(fmt.Sprintf("%s[%d]", o.label, i))
+				ji = jq("<details>").AddClass(ClassPrefix + "-slice-details").Append(summary).Append(ji)
+				ji.SetProp("open", sliceValue.Len() <= SliceCollapseAfter)
+			}
+			j.Append(newLi(j, ji, i))
 		}
-		addBtn := jq("<button>").SetText(SliceAddText)
-		addBtn.Call(jquery.CLICK, func() {
-			if sliceElemType.Kind() == reflect.Ptr {
-				newElem := reflect.New(sliceElemType.Elem())
-				sliceValue.Set(reflect.Append(sliceValue, newElem))
+		if sliceValue.Len() == 0 && o.emptyText != "" {
+			empty := jq("<span>").SetText(o.emptyText)
+			if o.table {
+				j.Append(fullRow(empty).AddClass(ClassPrefix + "-slice-empty"))
 			} else {
-				newElem := reflect.New(sliceElemType)
-				sliceValue.Set(reflect.Append(sliceValue, newElem.Elem()))
+				j.Append(jq("<li>").AddClass(ClassPrefix + "-slice-empty").Append(empty))
 			}
-			// Just delete and redo everything to work with non-pointers when the slice resizes
-			j.Empty()
-			e := populate()
-			if e != nil {
-				panic(e)
+		}
+		addBtn = jqInput("<button>").SetText(o.sliceText().SliceAdd)
+		addBtn.Call(jquery.CLICK, func() {
+			// Triggering the event programmatically ignores disabled
+			if addBtn.Is(":disabled") {
+				return
 			}
+			newSlice := reflect.ValueOf(copySlice(sliceValue))
+			commit(reflect.Append(newSlice, newElem()))
 		})
-		j.Append(addBtn)
+		if sliceElemType.Kind() == reflect.Interface && !proto.IsValid() {
+			// There's no way to know what type a new element should be
+			updateAddBtn()
+			return nil
+		}
+		if o.table {
+			j.Append(fullRow(addBtn).AddClass(ClassPrefix + "-slice-add"))
+		} else {
+			j.Append(jq("<li>").AddClass(ClassPrefix + "-slice-add").Append(addBtn))
+		}
+		updateAddBtn()
 		return nil
 	}
 
@@ -178,15 +924,129 @@ func Slice(slicePtr interface{}, title, id, class string, min, max, step float64
 	return j, nil
 }
 
+// onDrag makes item, the element at index i of a slice, draggable. dragged is shared by all of the elements of
+// the slice and holds the index of the one being dragged. Dropping an element onto item calls move with their
+// indexes.
+func onDrag(item jquery.JQuery, i int, dragged *int, move func(from, to int)) {
+	item.SetAttr("draggable", "true").AddClass(ClassPrefix + "-slice-drag")
+	item.On("dragstart", func(event jquery.Event) {
+		// The element shouldn't be dragged along with a slice it's in
+		event.StopPropagation()
+		*dragged = i
+		// Some browsers won't drag anything without data
+		if orig := event.Get("originalEvent"); orig != js.Undefined && orig.Get("dataTransfer") != js.Undefined {
+			orig.Get("dataTransfer").Call("setData", "text/plain", strconv.Itoa(i))
+		}
+	})
+	item.On("dragover", func(event jquery.Event) {
+		if *dragged >= 0 {
+			// Allow the drop
+			event.PreventDefault()
+		}
+	})
+	item.On("drop", func(event jquery.Event) {
+		if *dragged < 0 {
+			// Not one of this slice's elements
+			return
+		}
+		event.PreventDefault()
+		event.StopPropagation()
+		from := *dragged
+		*dragged = -1
+		if from != i {
+			move(from, i)
+		}
+	})
+	item.On("dragend", func() {
+		*dragged = -1
+	})
+}
+
+// restoreSlice sets the elements of dst to those of snapshot, which must be the same length. Elements that are
+// pointers keep pointing to the same place, only what they point to is restored.
+func restoreSlice(dst, snapshot reflect.Value) {
+	for i := 0; i < dst.Len(); i++ {
+		d, s := dst.Index(i), snapshot.Index(i)
+		if d.Kind() == reflect.Ptr && !d.IsNil() && !s.IsNil() {
+			d.Elem().Set(s.Elem())
+			continue
+		}
+		d.Set(s)
+	}
+}
+
+// nilToggle returns an unchecked checkbox that stands in for a nil pointer. Checking it calls enable, which should
+// allocate a value for the pointer.
+func nilToggle(o options, enable func()) jquery.JQuery {
+	box := jqInput("<input>").SetAttr("type", "checkbox")
+	box.Call(jquery.CHANGE, func() {
+		enable()
+	})
+	return jq("<label>").AddClass(ClassPrefix + "-nil").Append(box).Append(jq("<span>").SetText(o.labels.NilEnable))
+}
+
+// deepCopy returns a copy of v that shares no pointers, slices, or maps with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	cp := reflect.New(v.Type()).Elem()
+	switch v.Kind() {
+	case reflect.Ptr:
+		if !v.IsNil() {
+			cp.Set(reflect.New(v.Type().Elem()))
+			cp.Elem().Set(deepCopy(v.Elem()))
+		}
+	case reflect.Interface:
+		if !v.IsNil() {
+			cp.Set(deepCopy(v.Elem()))
+		}
+	case reflect.Slice:
+		if !v.IsNil() {
+			cp.Set(reflect.MakeSlice(v.Type(), v.Len(), v.Len()))
+			for i := 0; i < v.Len(); i++ {
+				cp.Index(i).Set(deepCopy(v.Index(i)))
+			}
+		}
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			cp.Index(i).Set(deepCopy(v.Index(i)))
+		}
+	case reflect.Map:
+		if !v.IsNil() {
+			cp.Set(reflect.MakeMap(v.Type()))
+			for _, key := range v.MapKeys() {
+				cp.SetMapIndex(key, deepCopy(v.MapIndex(key)))
+			}
+		}
+	case reflect.Struct:
+		// Unexported fields can't be copied individually so they're shared
+		cp.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if cp.Field(i).CanSet() {
+				cp.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+	default:
+		cp.Set(v)
+	}
+	return cp
+}
+
 // Bool takes a pointer to a bool value and returns a JQuery object associated with it in the form of a checkbox.
 // A non-nil error is returned in the event the conversion fails. The current value of the bool will be used as
 // the initial value of the checkbox.
-func Bool(b *bool, title, id, class string, valid Validator) (jquery.JQuery, error) {
-	j := jq("<input>").AddClass(ClassPrefix + "-bool").AddClass(class)
+func Bool(b *bool, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return boolControl(b, o)
+}
+
+func boolControl(b *bool, o options) (jquery.JQuery, error) {
+	j := jqInput("<input>").AddClass(o.typeClass("bool")).AddClass(o.class)
 	j.SetAttr("type", "checkbox")
-	j.SetAttr("title", title).SetAttr("id", id)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	valid := o.valid
 	j.SetProp("checked", *b)
-	j.SetData("prev", *b)
+	prev := *b
 	j.Call(jquery.CHANGE, func(event jquery.Event) {
 		val := event.Target.Get("checked").String()
 		bNew, e := strconv.ParseBool(val)
@@ -195,11 +1055,21 @@ func Bool(b *bool, title, id, class string, valid Validator) (jquery.JQuery, err
 			panic(fmt.Sprintf("value '%s' has invalid type, expected bool", val))
 		}
 		if valid != nil && !valid.Validate(bNew) {
-			bNew = j.Data("prev").(bool)
-			j.SetProp("checked", bNew)
+			j.SetProp("checked", prev)
+			return
+		}
+		old := prev
+		if !o.store(j, bNew, func() {
+			*b = bNew
+		}) {
+			return
 		}
-		*b = bNew
-		j.SetData("prev", bNew)
+		prev = bNew
+		o.didChange(j, old, bNew)
+	})
+	onRefresh(j, func() {
+		prev = *b
+		j.SetProp("checked", prev)
 	})
 	return j, nil
 }
@@ -209,106 +1079,668 @@ func Bool(b *bool, title, id, class string, valid Validator) (jquery.JQuery, err
 // error is returned in the event the conversion fails. The current value of the int will be used as the initial
 // value of the input.
 //
+// With WithClear the input is in a span with the ClassPrefix-clearable class, followed by the clear button. With
+// WithUnit the result is instead a span with the ClassPrefix-unit-wrap class holding the input, or the clearable
+// span, and the text.
+//
 // min, max, and step are float64 to allow the use of math.NaN() to indicate not to set the corresponding html
 // attribute. They will be truncated to ints otherwise.
-func Int(i *int, title, id, class string, min, max, step float64, valid Validator) (jquery.JQuery, error) {
-	j := jq("<input>").AddClass(ClassPrefix + "-int").AddClass(class)
-	j.SetAttr("title", title).SetAttr("id", id)
-	j.SetAttr("type", "number")
-	if !math.IsNaN(min) {
-		j.SetAttr("min", int(min))
+func Int(i *int, title, id, class string, min, max, step float64, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.min, o.max, o.step = min, max, step
+	o.valid = valid
+	o.apply(opts)
+	return intControl(i, o)
+}
+
+func intControl(i *int, o options) (jquery.JQuery, error) {
+	j := jqInput("<input>").AddClass(o.typeClass("int")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	if o.autocomplete != "" {
+		j.SetAttr("autocomplete", o.autocomplete)
+	}
+	if o.widget == "formatted" {
+		j.SetAttr("type", "text").SetAttr("inputmode", "numeric")
+	} else {
+		j.SetAttr("type", "number")
+	}
+	min, max, step, valid := o.min, o.max, o.step, o.valid
+	if o.noNative {
+		// Without a step the browser considers anything but whole numbers invalid
+		j.SetAttr("step", "any")
+	} else {
+		if !math.IsNaN(min) {
+			j.SetAttr("min", int(min))
+		}
+		if !math.IsNaN(max) {
+			j.SetAttr("max", int(max))
+		}
+		if !math.IsNaN(step) {
+			j.SetAttr("step", int(step))
+		}
 	}
-	if !math.IsNaN(max) {
-		j.SetAttr("max", int(max))
+	if o.optional.isNil() {
+		j.SetAttr("value", "")
+	} else {
+		j.SetAttr("value", *i)
 	}
-	if !math.IsNaN(step) {
-		j.SetAttr("step", int(step))
+	prev := *i
+	revert := func() {
+		if o.optional.isNil() {
+			j.SetVal("")
+			return
+		}
+		j.SetVal(prev)
 	}
-	j.SetAttr("value", *i)
-	j.SetData("prev", *i)
-	j.Call(jquery.CHANGE, func(event jquery.Event) {
-		val := event.Target.Get("value").String()
+	// update parses val and stores it in i. If final is false the user is still changing the value so it's
+	// ignored rather than reverted if it's invalid.
+	update := func(val string, final bool) {
+		if o.widget == "formatted" {
+			val = ungroupDigits(val)
+		}
+		if val == "" && o.optional != nil {
+			if final && !o.optional.isNil() {
+				if !o.store(j, nil, o.optional.setNil) {
+					return
+				}
+				j.RemoveClass(ClassPrefix + "-invalid")
+				o.didChange(j, prev, nil)
+			}
+			return
+		}
 		newI, e := strconv.Atoi(val)
 		if e != nil {
 			f, e := strconv.ParseFloat(val, 64)
 			if e != nil {
+				if !final {
+					return
+				}
+				if o.widget == "formatted" {
+					// Unlike a number input, a text input can hold anything
+					o.reject(j, revert)
+					return
+				}
 				panic(fmt.Errorf("value '%s' has invalid type, expected a number", val))
 			}
 			// Truncate to int
 			newI = int(f)
-			j.SetVal(newI)
+			if final {
+				j.SetVal(newI)
+			}
 		}
 		// Need to check for min and max ourselves because html min and max are easy to get around
 		isValid := valid == nil || valid.Validate(newI)
 		isToLow := !math.IsNaN(min) && newI < int(min)
 		isToHigh := !math.IsNaN(max) && newI > int(max)
-		if !isValid || isToLow || isToHigh {
-			newI = int(j.Data("prev").(float64))
-			j.SetVal(newI)
+		if !isValid || isToLow || isToHigh || !o.signOK(float64(newI)) {
+			if final {
+				o.reject(j, revert)
+			}
+			return
+		}
+		j.RemoveClass(ClassPrefix + "-invalid")
+		if newI == prev && !o.optional.isNil() {
+			// Already stored by a throttled update
+			return
 		}
-		*i = newI
-		j.SetData("prev", newI)
+		old := o.optional.value(prev)
+		if !o.store(j, newI, func() {
+			*i = newI
+			o.optional.set()
+		}) {
+			return
+		}
+		prev = newI
+		o.didChange(j, old, newI)
+	}
+	if e := onCommit(j, o, update); e != nil {
+		return jq(), e
+	}
+	onThrottledInput(j, o.throttle, func(event jquery.Event) {
+		update(event.Target.Get("value").String(), false)
 	})
-	return j, nil
+	onEnter(j, o.enter, func() {
+		update(j.Val(), true)
+	})
+	if o.keyFilter {
+		onKeyFilter(j, "0123456789+-"+GroupSeparator)
+	}
+	if o.widget == "formatted" {
+		onGroupDigits(j)
+	}
+	onShiftStep(j, o, func(text string) (float64, error) {
+		return strconv.ParseFloat(text, 64)
+	}, func(f float64) interface{} {
+		return int(f)
+	})
+	onRefresh(j, func() {
+		prev = *i
+		revert()
+	})
+	if o.clear {
+		return withUnit(clearable(j, o, func() {
+			if o.optional != nil {
+				j.SetVal("")
+				return
+			}
+			j.SetVal(0)
+		}), o), nil
+	}
+	return withUnit(j, o), nil
 }
 
 // Float64 takes a pointer to a float64 value and returns a JQuery object associated with it in the form of an
 // input of number type. A non-nil error is returned in the event the conversion fails. The current value of the
-// float64 will be used as the initial value of the input.
-func Float64(f *float64, title, id, class string, min, max, step float64, valid Validator) (jquery.JQuery, error) {
-	j := jq("<input>").AddClass(ClassPrefix + "-float64").AddClass(class)
-	j.SetAttr("title", title).SetAttr("id", id)
-	j.SetAttr("type", "number")
-	if !math.IsNaN(min) {
-		j.SetAttr("min", min)
-	}
-	if !math.IsNaN(max) {
-		j.SetAttr("max", max)
-	}
-	if !math.IsNaN(step) {
-		j.SetAttr("step", step)
-	}
-	j.SetAttr("value", *f)
-	j.SetData("prev", *f)
-	j.Call(jquery.CHANGE, func(event jquery.Event) {
-		val := event.Target.Get("value").String()
-		newF, e := strconv.ParseFloat(val, 64)
-		if e != nil {
-			panic(fmt.Errorf("value '%s' has invalid type, expected a number", val))
+// float64 will be used as the initial value of the input. If it is NaN or infinite then the input is left empty
+// with the value shown as a placeholder instead. Entries that aren't finite numbers are always rejected.
+//
+// As for Int, WithClear and WithUnit wrap the input in a span with the ClassPrefix-clearable or
+// ClassPrefix-unit-wrap class, which is returned instead.
+func Float64(f *float64, title, id, class string, min, max, step float64, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.min, o.max, o.step = min, max, step
+	o.valid = valid
+	o.apply(opts)
+	return float64Control(f, o)
+}
+
+func float64Control(f *float64, o options) (jquery.JQuery, error) {
+	j := jqInput("<input>").AddClass(o.typeClass("float64")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	if o.autocomplete != "" {
+		j.SetAttr("autocomplete", o.autocomplete)
+	}
+	if o.widget == "text" || o.widget == "formatted" || DecimalSeparator != "." {
+		j.SetAttr("type", "text").SetAttr("inputmode", "decimal")
+	} else {
+		j.SetAttr("type", "number")
+	}
+	min, max, step, valid := o.min, o.max, o.step, o.valid
+	if o.noNative {
+		j.SetAttr("step", "any")
+	} else {
+		if !math.IsNaN(min) {
+			j.SetAttr("min", min)
+		}
+		if !math.IsNaN(max) {
+			j.SetAttr("max", max)
+		}
+		if !math.IsNaN(step) {
+			j.SetAttr("step", step)
+		}
+	}
+	if o.optional.isNil() {
+		j.SetAttr("value", "")
+	} else if isFinite(*f) {
+		j.SetAttr("value", float64Val(*f))
+	} else {
+		j.SetAttr("placeholder", strconv.FormatFloat(*f, 'g', -1, 64))
+	}
+	prev := *f
+	revert := func() {
+		if o.optional.isNil() {
+			j.SetVal("")
+			return
+		}
+		setFloat64Val(j, prev)
+	}
+	// update parses val and stores it in f. If final is false the user is still changing the value so it's
+	// ignored rather than reverted if it's invalid.
+	update := func(val string, final bool) {
+		if val == "" && o.optional != nil {
+			if final && !o.optional.isNil() {
+				if !o.store(j, nil, o.optional.setNil) {
+					return
+				}
+				j.RemoveClass(ClassPrefix + "-invalid")
+				o.didChange(j, prev, nil)
+			}
+			return
+		}
+		if o.widget == "formatted" {
+			val = ungroupDigits(val)
+		}
+		newF, e := parseFloat64(val)
+		// The browser reports an empty value for things like "Infinity" so a parse failure isn't exceptional
+		if e != nil || !isFinite(newF) {
+			if final {
+				o.reject(j, revert)
+			}
+			return
+		}
+		if final {
+			j.SetVal(float64Val(newF))
 		}
-		j.SetVal(newF)
 		// Need to check for min and max ourselves because html min and max are easy to get around
 		isValid := valid == nil || valid.Validate(newF)
 		isToLow := !math.IsNaN(min) && newF < min
 		isToHigh := !math.IsNaN(max) && newF > max
-		if !isValid || isToLow || isToHigh {
-			newF = j.Data("prev").(float64)
-			j.SetVal(newF)
+		isTooPrecise := o.decimals >= 0 && decimalPlaces(strings.Replace(val, DecimalSeparator, ".", 1)) > o.decimals
+		if !isValid || isToLow || isToHigh || isTooPrecise || !o.signOK(newF) {
+			if final {
+				o.reject(j, revert)
+			}
+			return
 		}
-		*f = newF
-		j.SetData("prev", newF)
-	})
-	return j, nil
+		j.RemoveClass(ClassPrefix + "-invalid")
+		if newF == prev && !o.optional.isNil() {
+			// Already stored by a throttled update
+			return
+		}
+		old := o.optional.value(prev)
+		if !o.store(j, newF, func() {
+			*f = newF
+			o.optional.set()
+		}) {
+			return
+		}
+		prev = newF
+		o.didChange(j, old, newF)
+	}
+	if e := onCommit(j, o, update); e != nil {
+		return jq(), e
+	}
+	onThrottledInput(j, o.throttle, func(event jquery.Event) {
+		update(event.Target.Get("value").String(), false)
+	})
+	onEnter(j, o.enter, func() {
+		update(j.Val(), true)
+	})
+	if o.keyFilter {
+		onKeyFilter(j, "0123456789+-eE"+DecimalSeparator+GroupSeparator)
+	}
+	if o.widget == "formatted" {
+		onGroupDigits(j)
+	}
+	onShiftStep(j, o, parseFloat64, float64Val)
+	onRefresh(j, func() {
+		prev = *f
+		revert()
+	})
+	if o.clear {
+		return withUnit(clearable(j, o, func() {
+			if o.optional != nil {
+				j.SetVal("")
+				return
+			}
+			j.SetVal(0)
+		}), o), nil
+	}
+	return withUnit(j, o), nil
+}
+
+// onEnter calls commit when Enter is pressed in j. If mode is "next" then the next control created by this
+// package gets focus afterwards. Nothing is done if mode is empty.
+func onEnter(j jquery.JQuery, mode string, commit func()) {
+	if mode == "" {
+		return
+	}
+	j.Call(jquery.KEYDOWN, func(event jquery.Event) {
+		if event.Which != 13 {
+			return
+		}
+		event.PreventDefault()
+		commit()
+		if mode == "next" {
+			controls := jq(":input[class*='" + ClassPrefix + "-']:visible")
+			if i := controls.Index(j); i >= 0 && i+1 < controls.Length {
+				controls.Eq(i + 1).Focus()
+			}
+		}
+	})
+}
+
+// onShiftStep makes the up and down arrow keys change the number in j by o.shiftStep times the step, instead of
+// just the step, while Shift is held. The result is kept within min and max and committed like any other change.
+// parse and format convert between the number and the text of j.
+func onShiftStep(j jquery.JQuery, o options, parse func(string) (float64, error), format func(float64) interface{}) {
+	if o.shiftStep == 0 {
+		return
+	}
+	j.Call(jquery.KEYDOWN, func(event jquery.Event) {
+		if !event.ShiftKey || event.Which != 38 && event.Which != 40 {
+			return
+		}
+		f, e := parse(j.Val())
+		if e != nil {
+			return
+		}
+		step := o.step
+		if math.IsNaN(step) {
+			step = 1
+		}
+		if event.Which == 38 {
+			f += step * o.shiftStep
+		} else {
+			f -= step * o.shiftStep
+		}
+		if !math.IsNaN(o.min) {
+			f = math.Max(f, o.min)
+		}
+		if !math.IsNaN(o.max) {
+			f = math.Min(f, o.max)
+		}
+		event.PreventDefault()
+		j.SetVal(format(f))
+		j.Trigger(o.commitEvent())
+	})
+}
+
+// onKeyFilter keeps characters that aren't in allowed from being typed into j. Nothing is done if allowed is
+// empty. Pasting isn't affected.
+func onKeyFilter(j jquery.JQuery, allowed string) {
+	if allowed == "" {
+		return
+	}
+	j.Call(jquery.KEYPRESS, func(event jquery.Event) {
+		// Control characters, such as Enter, and shortcuts are let through
+		if event.Which < 32 || event.CtrlKey || event.MetaKey {
+			return
+		}
+		if !strings.ContainsRune(allowed, rune(event.Which)) {
+			event.PreventDefault()
+		}
+	})
+}
+
+// onThrottledInput calls fn for the input events of j, but no more than once per d. Nothing is done if d is 0.
+// The change event still fires when the user is done so the final value is never lost.
+func onThrottledInput(j jquery.JQuery, d time.Duration, fn func(jquery.Event)) {
+	if d <= 0 {
+		return
+	}
+	var last time.Time
+	j.On("input", func(event jquery.Event) {
+		if now := time.Now(); now.Sub(last) >= d {
+			last = now
+			fn(event)
+		}
+	})
+}
+
+// onCommit calls update with the text of j whenever o.commit says it should be stored. final is false while the
+// user is still typing, in which case an invalid value should be left alone rather than reverted. A non-nil error
+// is returned if o.commit isn't a known mode.
+func onCommit(j jquery.JQuery, o options, update func(val string, final bool)) error {
+	switch o.commit {
+	case "", "change", "blur":
+	case "input":
+		j.On("input", func(event jquery.Event) {
+			update(event.Target.Get("value").String(), false)
+		})
+	default:
+		return fmt.Errorf("unknown commit mode '%s'", o.commit)
+	}
+	j.Call(o.commitEvent(), func(event jquery.Event) {
+		update(event.Target.Get("value").String(), true)
+	})
+	return nil
+}
+
+// commitEvent returns the jQuery event that makes a control store what the user has entered.
+func (o options) commitEvent() string {
+	if o.commit == "blur" {
+		return jquery.BLUR
+	}
+	return jquery.CHANGE
+}
+
+// withUnit returns j between the prefix and unit that label it, if there are any.
+func withUnit(j jquery.JQuery, o options) jquery.JQuery {
+	if o.prefix == "" && o.unit == "" {
+		return j
+	}
+	wrap := jq("<span>").AddClass(ClassPrefix + "-unit-wrap")
+	if o.prefix != "" {
+		wrap.Append(jq("<span>").AddClass(ClassPrefix + "-unit-prefix").SetText(o.prefix))
+	}
+	wrap.Append(j)
+	if o.unit != "" {
+		wrap.Append(jq("<span>").AddClass(ClassPrefix + "-unit").SetText(o.unit))
+	}
+	return wrap
+}
+
+// clearable returns j followed by a button that calls clear, which should set j to its zero value, and then
+// triggers a commit. The zero value goes through the same checks as anything the user enters.
+func clearable(j jquery.JQuery, o options, clear func()) jquery.JQuery {
+	btn := jqInput("<button>").AddClass(ClassPrefix + "-clear").SetText(o.labels.Clear)
+	btn.Call(jquery.CLICK, func() {
+		clear()
+		j.Trigger(o.commitEvent())
+	})
+	return jq("<span>").AddClass(ClassPrefix + "-clearable").Append(j).Append(btn)
+}
+
+// reject is called when the user enters an invalid value into j. revert should put back the stored value. With
+// soft validation the invalid value is left alone instead and j is marked as invalid until a valid one is
+// entered.
+func (o options) reject(j jquery.JQuery, revert func()) {
+	if o.soft {
+		j.AddClass(ClassPrefix + "-invalid")
+		return
+	}
+	keepFocus(j, revert)
+}
+
+// FormValid returns false if root, or any control within it, holds an invalid value because of soft validation
+// (see WithSoftValidation).
+func FormValid(root jquery.JQuery) bool {
+	invalid := "." + ClassPrefix + "-invalid"
+	return !root.Is(invalid) && root.Find(invalid).Length == 0
+}
+
+// keepFocus calls fn, which should change the value of the input j, and then puts the focus and caret back the
+// way they were. Setting the value otherwise moves the caret to the end, which is disorienting when a change is
+// reverted while the user is still in the input.
+func keepFocus(j jquery.JQuery, fn func()) {
+	elem := j.Get(0)
+	focused := js.Global.Get("document").Get("activeElement") == elem
+	// Only text-like inputs have a caret, for other inputs the selection is null and other tags don't have one
+	start, end := elem.Get("selectionStart"), elem.Get("selectionEnd")
+	fn()
+	if !focused {
+		return
+	}
+	j.Focus()
+	if start != nil && end != nil && start != js.Undefined {
+		// The range is clamped to the length of the new value
+		elem.Call("setSelectionRange", start, end)
+	}
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// setFloat64Val sets the value of the number input j to f. NaN and infinite values clear the input since it
+// can't display them.
+func setFloat64Val(j jquery.JQuery, f float64) {
+	if !isFinite(f) {
+		j.SetVal("")
+		return
+	}
+	j.SetVal(float64Val(f))
+}
+
+// float64Val returns f as the value of an input. It's only formatted here when DecimalSeparator requires it,
+// otherwise it's left to the browser.
+func float64Val(f float64) interface{} {
+	if DecimalSeparator == "." {
+		return f
+	}
+	return strings.Replace(strconv.FormatFloat(f, 'f', -1, 64), ".", DecimalSeparator, 1)
+}
+
+// onGroupDigits makes j, an input of text type holding a number, show the number with GroupSeparator between groups
+// of digits while it doesn't have focus. The separators are taken out while it does, which makes editing easier.
+func onGroupDigits(j jquery.JQuery) {
+	group := func() {
+		if js.Global.Get("document").Get("activeElement") != j.Get(0) {
+			j.SetVal(groupDigits(j.Val()))
+		}
+	}
+	group()
+	j.On(jquery.FOCUS, func() {
+		j.SetVal(ungroupDigits(j.Val()))
+	})
+	j.On(jquery.BLUR, group)
+	onRefresh(j, group)
+}
+
+// groupDigits returns the number text with GroupSeparator between each group of three digits before the decimal
+// separator, e.g. "-1,234.5". Anything else, such as a number with an exponent, is returned as is.
+func groupDigits(text string) string {
+	text = ungroupDigits(text)
+	sign := ""
+	if strings.HasPrefix(text, "-") || strings.HasPrefix(text, "+") {
+		sign, text = text[:1], text[1:]
+	}
+	whole, frac := text, ""
+	if i := strings.Index(text, DecimalSeparator); i >= 0 {
+		whole, frac = text[:i], text[i:]
+	}
+	for _, r := range whole + strings.TrimPrefix(frac, DecimalSeparator) {
+		if r < '0' || r > '9' {
+			return sign + text
+		}
+	}
+	groups := []string{}
+	for len(whole) > 3 {
+		groups = append([]string{whole[len(whole)-3:]}, groups...)
+		whole = whole[:len(whole)-3]
+	}
+	groups = append([]string{whole}, groups...)
+	return sign + strings.Join(groups, GroupSeparator) + frac
+}
+
+// ungroupDigits returns text without any GroupSeparator.
+func ungroupDigits(text string) string {
+	return strings.Replace(text, GroupSeparator, "", -1)
+}
+
+// parseFloat64 parses text with DecimalSeparator in place of ".". Negative zero is returned as zero so it never
+// ends up stored.
+func parseFloat64(text string) (float64, error) {
+	if DecimalSeparator != "." {
+		text = strings.Replace(text, DecimalSeparator, ".", 1)
+	}
+	f, e := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if f == 0 {
+		// -0 == 0 so this drops the sign
+		f = 0
+	}
+	return f, e
+}
+
+// signOK returns false if f isn't greater than 0 when it must be positive, or is less than 0 when it must be
+// non-negative.
+func (o options) signOK(f float64) bool {
+	return !(o.positive && f <= 0) && !(o.nonNegative && f < 0)
+}
+
+// decimalPlaces returns how many digits the number text has after the decimal point once any exponent is applied,
+// not counting trailing zeros, e.g. 1 for "1.50" and 2 for "1.5e-1".
+func decimalPlaces(text string) int {
+	mantissa, exp := strings.TrimSpace(text), 0
+	if i := strings.IndexAny(mantissa, "eE"); i >= 0 {
+		exp, _ = strconv.Atoi(mantissa[i+1:])
+		mantissa = mantissa[:i]
+	}
+	places := 0
+	if i := strings.Index(mantissa, "."); i >= 0 {
+		places = len(strings.TrimRight(mantissa[i+1:], "0"))
+	}
+	if places -= exp; places < 0 {
+		return 0
+	}
+	return places
 }
 
 // String takes a pointer to a string value and returns a JQuery object associated with it in the form of an
 // input of text type. A non-nil error is returned in the event the conversion fails. The
 // current value of the string will be used as the initial value of the input.
-func String(s *string, title, id, class string, valid Validator) (jquery.JQuery, error) {
-	j := jq("<input>").AddClass(ClassPrefix + "-string").AddClass(class)
-	j.SetAttr("title", title).SetAttr("id", id)
-	j.SetAttr("type", "text")
-	j.SetAttr("value", *s)
-	j.SetData("prev", *s)
-	j.Call(jquery.CHANGE, func(event jquery.Event) {
-		newS := event.Target.Get("value").String()
-		if valid != nil && !valid.Validate(newS) {
-			newS = j.Data("prev").(string)
-			j.SetVal(newS)
+func String(s *string, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return stringControl(s, o)
+}
+
+func stringControl(s *string, o options) (jquery.JQuery, error) {
+	j := jqInput("<input>").SetAttr("type", "text")
+	if o.multiline {
+		j = jqInput("<textarea>")
+	}
+	j.AddClass(o.typeClass("string")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	valid := o.valid
+	j.SetVal(*s)
+	if o.widget == "code" {
+		j.AddClass(o.typeClass("code"))
+		j.SetAttr("spellcheck", "false").SetAttr("autocapitalize", "off").SetAttr("autocomplete", "off")
+	}
+	if o.autocomplete != "" {
+		j.SetAttr("autocomplete", o.autocomplete)
+	}
+	if o.maxLen > 0 && !o.noNative {
+		j.SetAttr("maxlength", o.maxLen)
+	}
+	prev := *s
+	checkAsync := o.asyncChecker(j, *s, func(confirmed interface{}) {
+		old := *s
+		if !o.store(j, confirmed, func() {
+			*s = confirmed.(string)
+		}) {
+			return
+		}
+		prev = confirmed.(string)
+		// Leave what the user has typed since alone
+		if j.Val() == old {
+			j.SetVal(prev)
 		}
-		*s = newS
-		j.SetData("prev", newS)
+		o.didChange(j, old, prev)
+	})
+	// update stores newS. If final is false the user is still typing so it's ignored rather than reverted if it's
+	// invalid.
+	update := func(newS string, final bool) {
+		if checkLen(newS, o) != nil || valid != nil && !valid.Validate(newS) {
+			if final {
+				o.reject(j, func() {
+					j.SetVal(prev)
+				})
+			}
+			return
+		}
+		j.RemoveClass(ClassPrefix + "-invalid")
+		if newS == prev && o.commit == "input" {
+			// Already stored while typing
+			return
+		}
+		old := prev
+		if !o.store(j, newS, func() {
+			*s = newS
+		}) {
+			return
+		}
+		prev = newS
+		o.didChange(j, old, newS)
+		checkAsync(newS)
+	}
+	if e := onCommit(j, o, update); e != nil {
+		return jq(), e
+	}
+	onRefresh(j, func() {
+		prev = *s
+		j.SetVal(prev)
 	})
+	if o.clear {
+		return clearable(j, o, func() {
+			j.SetVal("")
+		}), nil
+	}
 	return j, nil
 }
 
@@ -316,9 +1748,21 @@ func String(s *string, title, id, class string, valid Validator) (jquery.JQuery,
 // associated with it in the form of a choice tag. A non-nil error is returned in the event the conversion
 // fails. If s is the empty string then the initial value is choices[0]. If it is not empty but not in choices
 // then A non-nil error is returned. If s is in choices then it is used as the intial value.
-func Choice(s *string, choices []string, title, id, class string, valid Validator) (jquery.JQuery, error) {
-	j := jq("<select>").AddClass(ClassPrefix + "-choice").AddClass(class)
-	j.SetAttr("title", title).SetAttr("id", id)
+func Choice(s *string, choices []string, title, id, class string, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.choices = choices
+	o.valid = valid
+	o.apply(opts)
+	return choiceControl(s, o)
+}
+
+func choiceControl(s *string, o options) (jquery.JQuery, error) {
+	// A choice is stored as soon as it's picked, whatever the commit mode
+	o.commit = ""
+	j := jqInput("<select>").AddClass(o.typeClass("choice")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	choices, valid := o.choices, o.valid
 	if *s == "" {
 		*s = choices[0]
 	}
@@ -332,44 +1776,565 @@ func Choice(s *string, choices []string, title, id, class string, valid Validato
 	if index == -1 {
 		return jq(), fmt.Errorf("Default of '%s' is not among valid choices", *s)
 	}
-	j.SetData("prev", index)
+	prev := index
 	j.SetProp("selectedIndex", index)
 	j.Call(jquery.CHANGE, func(event jquery.Event) {
 		newS := event.Target.Get("value").String()
 		newIndex := event.Target.Get("selectedIndex").Int()
 		if valid != nil && !valid.Validate(newS) {
-			newIndex = int(j.Data("prev").(float64))
-			j.SetProp("selectedIndex", newIndex)
+			j.SetProp("selectedIndex", prev)
+			return
+		}
+		old := *s
+		if !o.store(j, choices[newIndex], func() {
+			*s = choices[newIndex]
+		}) {
+			return
+		}
+		prev = newIndex
+		o.didChange(j, old, *s)
+	})
+	onRefresh(j, func() {
+		if index := indexOf(choices, *s); index != -1 {
+			prev = index
+			j.SetProp("selectedIndex", index)
+		}
+	})
+	j.On(setChoicesEvent, func() {
+		setChoices.handled = true
+		choices = setChoices.choices
+		j.Empty()
+		index := 0
+		for i, c := range choices {
+			if c == *s {
+				index = i
+			}
+			j.Append(jq("<option>").SetAttr("value", c).SetText(c))
+		}
+		prev = index
+		j.SetProp("selectedIndex", index)
+		if choices[index] != *s {
+			old := *s
+			if !o.store(j, choices[index], func() {
+				*s = choices[index]
+			}) {
+				return
+			}
+			o.didChange(j, old, *s)
 		}
-		*s = choices[int(newIndex)]
-		j.SetData("prev", newIndex)
 	})
+	if o.clear {
+		return clearable(j, o, func() {
+			j.SetProp("selectedIndex", 0)
+		}), nil
+	}
 	return j, nil
 }
 
-func convert(val reflect.Value, title, id, class, choices string, min, max, step float64, valid Validator) (jquery.JQuery, error) {
+// setChoicesEvent is the jQuery event that SetChoices triggers on a Choice control.
+const setChoicesEvent = "setchoices." + EventNamespace
+
+// setChoices holds the arguments for the setChoicesEvent currently being handled, and whether a control handled
+// it.
+var setChoices struct {
+	choices []string
+	handled bool
+}
+
+// SetChoices replaces the choices of j, which should have been returned by Choice or made by Struct for a field
+// with a choice tag. The current value is kept if it's among the new choices, otherwise the value becomes
+// choices[0]. A non-nil error is returned if choices is empty or j isn't a Choice.
+func SetChoices(j jquery.JQuery, choices []string) error {
+	if len(choices) == 0 {
+		return fmt.Errorf("choices should not be empty")
+	}
+	setChoices.choices, setChoices.handled = choices, false
+	choiceSelect(j).TriggerHandler(setChoicesEvent)
+	if !setChoices.handled {
+		return fmt.Errorf("not a choice control")
+	}
+	return nil
+}
+
+// choiceSelect returns the <select> of the Choice j, which is inside a wrapper when the Choice is clearable.
+func choiceSelect(j jquery.JQuery) jquery.JQuery {
+	if j.Is("select") {
+		return j
+	}
+	return j.Find("select").First()
+}
+
+// LinkChoices makes the choices of the Choice child depend on the value of the Choice parent, such as a list of
+// states that depends on the chosen country. Whenever parent changes, the child's choices are replaced by
+// choicesFor(value) as if by SetChoices. choicesFor should always return at least one choice; when it returns none
+// the child keeps its previous choices and is marked as invalid (see FormValid) until the parent changes to a value
+// that has some. The child's choices are also replaced right away, and a non-nil error is returned if that fails.
+func LinkChoices(parent, child jquery.JQuery, choicesFor func(value string) []string) error {
+	if e := SetChoices(child, choicesFor(choiceSelect(parent).Val())); e != nil {
+		return e
+	}
+	OnChange(parent, func(old, new interface{}) {
+		// With no choices the child keeps its old ones, which don't belong to the parent's value anymore
+		if e := SetChoices(child, choicesFor(new.(string))); e != nil {
+			choiceSelect(child).AddClass(ClassPrefix + "-invalid")
+			return
+		}
+		choiceSelect(child).RemoveClass(ClassPrefix + "-invalid")
+	})
+	return nil
+}
+
+// ChoiceFilter is like Choice but instead of a choice tag it returns a JQuery object containing a text input
+// and a list of the choices. Typing in the input narrows the list down to the choices that contain the text and
+// clicking one selects it. The input only ever keeps a value from choices, anything else that is typed is
+// discarded when it loses focus. This is more manageable than Choice when there are many choices.
+func ChoiceFilter(s *string, choices []string, title, id, class string, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.choices = choices
+	o.valid = valid
+	o.apply(opts)
+	return choiceFilterControl(s, o)
+}
+
+func choiceFilterControl(s *string, o options) (jquery.JQuery, error) {
+	j := jq("<div>").AddClass(o.typeClass("choice-filter")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	choices, valid := o.choices, o.valid
+	if *s == "" {
+		*s = choices[0]
+	}
+	found := false
+	for _, c := range choices {
+		if c == *s {
+			found = true
+		}
+	}
+	if !found {
+		return jq(), fmt.Errorf("Default of '%s' is not among valid choices", *s)
+	}
+
+	input := jqInput("<input>").AddClass(ClassPrefix + "-choice-filter-input")
+	input.SetAttr("type", "text")
+	input.SetAttr("value", *s)
+	list := jq("<ul>").AddClass(ClassPrefix + "-choice-filter-list").Hide()
+	options := make([]jquery.JQuery, len(choices))
+	for i, c := range choices {
+		c := c
+		opt := jq("<li>").SetText(c)
+		// Use mousedown instead of click so that the input doesn't lose focus and hide the list first
+		opt.Call(jquery.MOUSEDOWN, func(event jquery.Event) {
+			event.PreventDefault()
+			if c != *s && (valid == nil || valid.Validate(c)) {
+				old := *s
+				if o.store(input, c, func() {
+					*s = c
+				}) {
+					o.didChange(input, old, c)
+				}
+			}
+			input.SetVal(*s)
+			list.Hide()
+		})
+		options[i] = opt
+		list.Append(opt)
+	}
+	filter := func() {
+		text := strings.ToLower(input.Val())
+		for i, c := range choices {
+			if strings.Contains(strings.ToLower(c), text) {
+				options[i].Show()
+			} else {
+				options[i].Hide()
+			}
+		}
+		list.Show()
+	}
+	input.Call(jquery.FOCUS, func() {
+		// Start with the full list, the current value isn't useful as a filter
+		input.SetVal("")
+		filter()
+	})
+	input.On("input", filter)
+	input.Call(jquery.BLUR, func() {
+		input.SetVal(*s)
+		list.Hide()
+	})
+	onRefresh(j, func() {
+		input.SetVal(*s)
+	})
+
+	j.Append(input).Append(list)
+	return j, nil
+}
+
+// radioGroups is used to give each group of radio buttons a unique name.
+var radioGroups = 0
+
+// Radio is like Choice but returns a JQuery object containing a radio button for each of the choices instead of
+// a choice tag. The button matching the initial value of s is selected. This is clearer than Choice when there
+// are only a few choices.
+func Radio(s *string, choices []string, title, id, class string, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.choices = choices
+	o.valid = valid
+	o.apply(opts)
+	return radioControl(s, o)
+}
+
+func radioControl(s *string, o options) (jquery.JQuery, error) {
+	j := jq("<div>").AddClass(o.typeClass("radio")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	choices, valid := o.choices, o.valid
+	if *s == "" {
+		*s = choices[0]
+	}
+	radioGroups++
+	name := fmt.Sprintf("%s-radio-%d", ClassPrefix, radioGroups)
+	index := -1
+	buttons := make([]jquery.JQuery, len(choices))
+	for i, c := range choices {
+		if c == *s {
+			index = i
+		}
+		btn := jqInput("<input>").SetAttr("type", "radio").SetAttr("name", name).SetAttr("value", c)
+		buttons[i] = btn
+		j.Append(jq("<label>").Append(btn).Append(jq("<span>").SetText(c)))
+	}
+	if index == -1 {
+		return jq(), fmt.Errorf("Default of '%s' is not among valid choices", *s)
+	}
+	buttons[index].SetProp("checked", true)
+	prev := index
+	for i, btn := range buttons {
+		i, btn := i, btn
+		btn.Call(jquery.CHANGE, func() {
+			if valid != nil && !valid.Validate(choices[i]) {
+				buttons[prev].SetProp("checked", true)
+				return
+			}
+			old := *s
+			if !o.store(btn, choices[i], func() {
+				*s = choices[i]
+			}) {
+				return
+			}
+			prev = i
+			o.didChange(btn, old, *s)
+		})
+	}
+	onRefresh(j, func() {
+		if index := indexOf(choices, *s); index != -1 {
+			prev = index
+			buttons[index].SetProp("checked", true)
+		}
+	})
+	return j, nil
+}
+
+// render is the same as convert but it calls the render hooks around it and gives any error the path to val.
+// Containers use it for the values within them.
+func render(val reflect.Value, o options) (jquery.JQuery, error) {
+	if o.beforeRender != nil {
+		o.beforeRender(o.path)
+	}
+	j, e := convert(val, o)
+	if e != nil {
+		return j, atPath(o.path, e)
+	}
+	setTabIndex(j, o)
+	if o.afterRender != nil {
+		o.afterRender(o.path, j)
+	}
+	return j, nil
+}
+
+// setTabIndex gives every input within j, and j itself if it's one, the tabindex from o if there is one.
+// Containers call it again after redoing their contents, since the new inputs don't have it.
+func setTabIndex(j jquery.JQuery, o options) {
+	if o.tabIndex != "" {
+		j.Find(":input").Add(j.Filter(":input")).SetAttr("tabindex", o.tabIndex)
+	}
+}
+
+// pathError is an error converting the value at path, which is where the value is within the top-level control.
+type pathError struct {
+	path string
+	err  error
+}
+
+func (e *pathError) Error() string {
+	return fmt.Sprintf("%s: %s", e.path, e.err)
+}
+
+// atPath returns e as an error about the value at path, e.g. "Sub.Items[2]: unsupported type chan". An error that
+// already has a path, because it's about a value further within, is returned as is.
+func atPath(path string, e error) error {
+	switch e.(type) {
+	case *pathError, fieldErrors:
+		return e
+	}
+	if path == "" {
+		return e
+	}
+	return &pathError{path, e}
+}
+
+// fieldErrors are the errors of several fields of a struct, each of which has a path.
+type fieldErrors []error
+
+func (errs fieldErrors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Error()
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add returns errs with e added, or the errors within e if it holds several itself.
+func (errs fieldErrors) add(e error) fieldErrors {
+	if more, ok := e.(fieldErrors); ok {
+		return append(errs, more...)
+	}
+	return append(errs, e)
+}
+
+// unsupportedError is the error for a value whose type isn't supported by this package.
+type unsupportedError struct {
+	kind reflect.Kind
+}
+
+func (e *unsupportedError) Error() string {
+	return fmt.Sprintf("unsupported type %s", e.kind)
+}
+
+// isUnsupported reports whether e is because the value's type isn't supported, rather than because of a problem
+// with a supported one such as a bad tag.
+func isUnsupported(e error) bool {
+	_, ok := e.(*unsupportedError)
+	return ok
+}
+
+// stringEnums maps each string type given to RegisterStringEnum to its choices.
+var stringEnums = make(map[reflect.Type][]string)
+
+// RegisterStringEnum sets the choices for all values of the string type t, such as a type with a constant for
+// each of its possible values. Struct, Slice, and Map then convert them as if they had a choice tag, unless they
+// have one of their own.
+func RegisterStringEnum(t reflect.Type, choices []string) {
+	stringEnums[t] = choices
+}
+
+// basicTypes are the types of each kind that the controls are bound to.
+var basicTypes = map[reflect.Kind]reflect.Type{
+	reflect.Bool:    reflect.TypeOf(false),
+	reflect.Int:     reflect.TypeOf(0),
+	reflect.Float64: reflect.TypeOf(0.0),
+	reflect.String:  reflect.TypeOf(""),
+}
+
+// convert returns the control for val, marked with the kind of value it's for (see FindByType).
+func convert(val reflect.Value, o options) (jquery.JQuery, error) {
+	j, e := convertValue(val, o)
+	if e == nil {
+		j.SetAttr(kindAttr(), kindOf(val).String())
+	}
+	return j, e
+}
+
+// convertValue returns the control for val. describeValue should be kept in line with the choices made here.
+func convertValue(val reflect.Value, o options) (jquery.JQuery, error) {
+	if !val.CanAddr() && val.Kind() != reflect.Ptr {
+		// Edit an addressable copy instead and store it back into the container after every change
+		if o.set == nil {
+			return jq(), fmt.Errorf("%s value is not addressable", val.Type().Kind())
+		}
+		cp := reflect.New(val.Type()).Elem()
+		cp.Set(val)
+		set, changed, store := o.set, o.changed, o.store
+		o.changed = func() {
+			// The control has already checked that it isn't read-only when storing into the copy
+			store(jq(), cp.Interface(), func() {
+				set(cp)
+			})
+			if changed != nil {
+				changed()
+			}
+		}
+		// Nothing else can see the copy, so writes to it don't need to go through the setter
+		o.set, o.setter = nil, nil
+		val = cp
+	}
+	if val.Kind() == reflect.Interface {
+		if val.IsNil() {
+			return jq(), fmt.Errorf("nil %s", val.Type())
+		}
+		// A pointer can be edited directly, otherwise the concrete value is a copy that has to be set back
+		iface := val
+		o.set = func(v reflect.Value) {
+			iface.Set(v)
+		}
+		return convert(iface.Elem(), o)
+	}
+	// A pointer to a pointer is followed down to the last pointer. Nil ones along the way are given a value that's
+	// set as part of the first store, so that it goes through the setter
+	for val.Kind() == reflect.Ptr && val.Type().Elem().Kind() == reflect.Ptr {
+		if val.IsNil() {
+			if !val.CanSet() {
+				return jq(), fmt.Errorf("nil %s", val.Type())
+			}
+			ptr, alloc, setter := val, reflect.New(val.Type().Elem()), o.setter
+			o.setter = func(path string, new interface{}, write func()) {
+				attach := func() {
+					if ptr.IsNil() {
+						ptr.Set(alloc)
+					}
+					write()
+				}
+				if setter == nil {
+					attach()
+					return
+				}
+				setter(path, new, attach)
+			}
+			val = alloc
+		}
+		val = val.Elem()
+	}
+	typ := val.Type()
 	kind := val.Type().Kind()
 	intf := val.Addr().Interface()
 	if val.Type().Kind() == reflect.Ptr {
+		typ = val.Type().Elem()
 		kind = val.Type().Elem().Kind()
 		intf = val.Interface()
 	}
+	// A pointer to a number may be set to nil by emptying the input
+	if val.Kind() == reflect.Ptr && (kind == reflect.Int || kind == reflect.Float64) && val.CanSet() {
+		o.optional = newOptional(val)
+		intf = o.optional.target.Interface()
+	}
+	if m, ok := intf.(ControlMarshaler); ok {
+		return m.MarshalControl(o.title, o.id, o.class)
+	}
+	if o.valid == nil {
+		switch kind {
+		case reflect.Bool, reflect.Int, reflect.Float64, reflect.String:
+			o.valid = typeValidators[kind]
+		}
+	}
+	if enum, ok := stringEnums[typ]; ok && o.choices == nil {
+		o.choices = enum
+	}
+	// Types that need special treatment before going by kind
+	switch typ {
+	case rawMessageType:
+		return rawJSONControl(intf.(*json.RawMessage), o)
+	case timeType:
+		switch o.widget {
+		case "":
+		case "ago":
+			return timeAgoControl(intf.(*time.Time), o)
+		default:
+			return jq(), fmt.Errorf("unknown widget '%s' for time", o.widget)
+		}
+		return timeControl(intf.(*time.Time), o)
+	case callbackType:
+		return callbackControl(intf.(*func()), o)
+	}
+	// Named types, such as type Mode string, are edited through a pointer to the type they're based on
+	if base, ok := basicTypes[kind]; ok && typ != base {
+		intf = reflect.ValueOf(intf).Convert(reflect.PtrTo(base)).Interface()
+	}
 	switch kind {
 	case reflect.Struct:
-		return Struct(intf, title, id, class)
+		return structControl(intf, o)
+	case reflect.Map:
+		return mapControl(intf, o)
 	case reflect.Slice:
-		return Slice(intf, title, id, class, min, max, step, valid)
+		if o.image {
+			return imageControl(intf, o)
+		}
+		if o.encoding != "" {
+			return bytesControl(intf, o)
+		}
+		switch o.widget {
+		case "":
+		case "lines":
+			return sliceLinesControl(intf, o)
+		case "multiselect":
+			return multiSelectControl(intf, o)
+		case "table":
+			o.table = true
+		case "draggable":
+			o.draggable = true
+		default:
+			return jq(), fmt.Errorf("unknown widget '%s' for slice", o.widget)
+		}
+		if o.csv {
+			return sliceCSVControl(intf, o)
+		}
+		return sliceControl(intf, o)
 	case reflect.Bool:
-		return Bool(intf.(*bool), title, id, class, valid)
+		switch o.widget {
+		case "":
+		case "buttons":
+			return boolButtonsControl(intf.(*bool), o)
+		default:
+			return jq(), fmt.Errorf("unknown widget '%s' for bool", o.widget)
+		}
+		return boolControl(intf.(*bool), o)
 	case reflect.Int:
-		return Int(intf.(*int), title, id, class, min, max, step, valid)
+		switch o.widget {
+		case "":
+		case "logrange":
+			return logRangeControl(intf, o)
+		case "formatted":
+		default:
+			return jq(), fmt.Errorf("unknown widget '%s' for int", o.widget)
+		}
+		return intControl(intf.(*int), o)
 	case reflect.Float64:
-		return Float64(intf.(*float64), title, id, class, min, max, step, valid)
+		switch o.widget {
+		case "", "text", "formatted":
+		case "percent":
+			return percentControl(intf.(*float64), o)
+		case "logrange":
+			return logRangeControl(intf, o)
+		default:
+			return jq(), fmt.Errorf("unknown widget '%s' for float64", o.widget)
+		}
+		return float64Control(intf.(*float64), o)
 	case reflect.String:
-		if choices != "" {
-			return Choice(intf.(*string), strings.Split(choices, ","), title, id, class, valid)
+		if o.choices != nil {
+			switch o.widget {
+			case "":
+			case "filter":
+				return choiceFilterControl(intf.(*string), o)
+			case "radio":
+				return radioControl(intf.(*string), o)
+			default:
+				return jq(), fmt.Errorf("unknown widget '%s' for choice", o.widget)
+			}
+			return choiceControl(intf.(*string), o)
+		}
+		switch o.widget {
+		case "":
+		case "secret":
+			return secretControl(intf.(*string), o)
+		case "boolstring":
+			return boolStringControl(intf.(*string), o)
+		case "code":
+		case "filter", "radio":
+			return jq(), fmt.Errorf("widget '%s' requires a choice tag", o.widget)
+		default:
+			return jq(), fmt.Errorf("unknown widget '%s' for string", o.widget)
+		}
+		if o.counter {
+			return stringCounterControl(intf.(*string), o)
 		}
-		return String(intf.(*string), title, id, class, valid)
+		return stringControl(intf.(*string), o)
 	}
-	return jq(), fmt.Errorf("unsupported type %s", val.Type().Kind())
+	return jq(), &unsupportedError{val.Type().Kind()}
 }
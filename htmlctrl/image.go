@@ -0,0 +1,116 @@
+package htmlctrl
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/gopherjs/jquery"
+)
+
+// imageTypes are the media types of the images that an image preview recognizes, by the bytes they start with.
+var imageTypes = []struct {
+	magic     []byte
+	mediaType string
+}{
+	{[]byte("\x89PNG\r\n\x1a\n"), "image/png"},
+	{[]byte("\xff\xd8\xff"), "image/jpeg"},
+	{[]byte("GIF8"), "image/gif"},
+}
+
+// Image takes a pointer to a byte slice holding a PNG, JPEG, or GIF image and returns a JQuery object associated
+// with it in the form of an img tag previewing it plus an input of file type for replacing it. A non-nil error is
+// returned in the event the conversion fails. Once a file is chosen it's read and its bytes are stored, unless
+// they aren't an image of one of those types or they don't pass valid. Problems are shown next to the input using
+// the ImageType and ImageInvalid labels.
+//
+// valid, if not nil, is given the bytes of the file.
+func Image(slicePtr interface{}, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.image = true
+	o.valid = valid
+	o.apply(opts)
+	return imageControl(slicePtr, o)
+}
+
+func imageControl(slicePtr interface{}, o options) (jquery.JQuery, error) {
+	t, v := reflect.TypeOf(slicePtr), reflect.ValueOf(slicePtr)
+	if t.Kind() != reflect.Ptr {
+		return jq(), fmt.Errorf("slicePtr should be a pointer, got %s instead", t.Kind())
+	}
+	if t.Elem().Kind() != reflect.Slice || t.Elem().Elem().Kind() != reflect.Uint8 {
+		return jq(), fmt.Errorf("slicePtr should be a pointer to slice of bytes, got pointer to %s instead", t.Elem())
+	}
+	sliceValue := v.Elem()
+
+	j := jq("<span>").AddClass(o.typeClass("image")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	img := jq("<img>").AddClass(ClassPrefix + "-image-preview")
+	input := jqInput("<input>").SetAttr("type", "file").SetAttr("accept", "image/png,image/jpeg,image/gif")
+	errText := jq("<span>").AddClass(ClassPrefix + "-image-error")
+	// update shows the image that's stored, if any
+	update := func() {
+		b := sliceValue.Bytes()
+		if len(b) == 0 {
+			img.RemoveAttr("src").Hide()
+			return
+		}
+		img.SetAttr("src", "data:"+imageType(b)+";base64,"+base64.StdEncoding.EncodeToString(b)).Show()
+	}
+	update()
+	input.Call(jquery.CHANGE, func(event jquery.Event) {
+		errText.SetText("")
+		files := event.Target.Get("files")
+		if files == nil || files.Length() == 0 {
+			return
+		}
+		reader := js.Global.Get("FileReader").New()
+		reader.Set("onload", func() {
+			arr := js.Global.Get("Uint8Array").New(reader.Get("result"))
+			b := make([]byte, arr.Length())
+			for i := range b {
+				b[i] = byte(arr.Index(i).Int())
+			}
+			if imageType(b) == "" {
+				errText.SetText(o.labels.ImageType)
+				return
+			}
+			if o.valid != nil && !o.valid.Validate(b) {
+				errText.SetText(o.labels.ImageInvalid)
+				return
+			}
+			old := copySlice(sliceValue)
+			newSlice := reflect.ValueOf(b).Convert(sliceValue.Type())
+			if !o.store(input, newSlice.Interface(), func() {
+				sliceValue.Set(newSlice)
+			}) {
+				return
+			}
+			update()
+			o.didChange(input, old, newSlice.Interface())
+		})
+		reader.Set("onerror", func() {
+			errText.SetText(reader.Get("error").String())
+		})
+		reader.Call("readAsArrayBuffer", files.Index(0))
+	})
+	onRefresh(j, func() {
+		input.SetVal("")
+		errText.SetText("")
+		update()
+	})
+	j.Append(img).Append(input).Append(errText)
+	return j, nil
+}
+
+// imageType returns the media type of the image in b, or "" if it isn't one that's recognized.
+func imageType(b []byte) string {
+	for _, t := range imageTypes {
+		if bytes.HasPrefix(b, t.magic) {
+			return t.mediaType
+		}
+	}
+	return ""
+}
@@ -0,0 +1,71 @@
+package htmlctrl
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+var rawMessageType = reflect.TypeOf(json.RawMessage{})
+
+// RawJSON takes a pointer to a json.RawMessage and returns a JQuery object associated with it in the form of a
+// textarea holding the indented JSON. A non-nil error is returned in the event the conversion fails. Changes that
+// aren't well-formed JSON are reverted, or marked as invalid with soft validation (see WithSoftValidation),
+// otherwise the compacted JSON is stored. An empty textarea stores nil.
+//
+// valid, if not nil, is given the compacted json.RawMessage.
+func RawJSON(msg *json.RawMessage, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return rawJSONControl(msg, o)
+}
+
+func rawJSONControl(msg *json.RawMessage, o options) (jquery.JQuery, error) {
+	j := jqInput("<textarea>").AddClass(o.typeClass("json")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetAttr("spellcheck", "false")
+	j.SetVal(indentJSON(*msg))
+	revert := func() {
+		j.SetVal(indentJSON(*msg))
+	}
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		text := []byte(event.Target.Get("value").String())
+		var newMsg json.RawMessage
+		if len(bytes.TrimSpace(text)) > 0 {
+			if !json.Valid(text) {
+				o.reject(j, revert)
+				return
+			}
+			var buf bytes.Buffer
+			json.Compact(&buf, text)
+			newMsg = buf.Bytes()
+		}
+		if o.valid != nil && !o.valid.Validate(newMsg) {
+			o.reject(j, revert)
+			return
+		}
+		j.RemoveClass(ClassPrefix + "-invalid")
+		old := *msg
+		if !o.store(j, newMsg, func() {
+			*msg = newMsg
+		}) {
+			return
+		}
+		j.SetVal(indentJSON(newMsg))
+		o.didChange(j, old, newMsg)
+	})
+	onRefresh(j, revert)
+	return j, nil
+}
+
+// indentJSON returns msg formatted for editing. It's returned as is if it isn't valid.
+func indentJSON(msg json.RawMessage) string {
+	var buf bytes.Buffer
+	if e := json.Indent(&buf, msg, "", "  "); e != nil {
+		return string(msg)
+	}
+	return buf.String()
+}
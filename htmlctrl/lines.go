@@ -0,0 +1,77 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gopherjs/jquery"
+)
+
+// SliceLines takes a pointer to a slice of strings and returns a JQuery object associated with it in the form
+// of a textarea with one element per line. A non-nil error is returned in the event the conversion fails. Empty
+// lines are dropped so an empty textarea is an empty slice. This suits lists that are usually pasted in.
+//
+// Every line must pass valid, otherwise the whole change is reverted.
+func SliceLines(slicePtr interface{}, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return sliceLinesControl(slicePtr, o)
+}
+
+func sliceLinesControl(slicePtr interface{}, o options) (jquery.JQuery, error) {
+	t, v := reflect.TypeOf(slicePtr), reflect.ValueOf(slicePtr)
+	if t.Kind() != reflect.Ptr {
+		return jq(), fmt.Errorf("slicePtr should be a pointer, got %s instead", t.Kind())
+	}
+	if t.Elem().Kind() != reflect.Slice || t.Elem().Elem().Kind() != reflect.String {
+		return jq(), fmt.Errorf("slicePtr should be a pointer to slice of strings, got pointer to %s instead", t.Elem())
+	}
+	sliceValue := v.Elem()
+
+	j := jqInput("<textarea>").AddClass(o.typeClass("slice-lines")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetVal(formatLines(sliceValue))
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		newSlice, e := parseLines(event.Target.Get("value").String(), sliceValue.Type(), o)
+		if e == nil {
+			old := copySlice(sliceValue)
+			if o.store(j, newSlice.Interface(), func() {
+				sliceValue.Set(newSlice)
+			}) {
+				o.didChange(j, old, newSlice.Interface())
+			}
+		}
+		j.SetVal(formatLines(sliceValue))
+	})
+	onRefresh(j, func() {
+		j.SetVal(formatLines(sliceValue))
+	})
+	return j, nil
+}
+
+func formatLines(sliceValue reflect.Value) string {
+	lines := make([]string, sliceValue.Len())
+	for i := range lines {
+		lines[i] = sliceValue.Index(i).String()
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseLines returns a new slice of type sliceType holding the non-empty lines of text.
+func parseLines(text string, sliceType reflect.Type, o options) (reflect.Value, error) {
+	newSlice := reflect.MakeSlice(sliceType, 0, 0)
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		elem, e := parseValue(line, reflect.String, o)
+		if e != nil {
+			return newSlice, fmt.Errorf("line %d: %s", i, e)
+		}
+		newSlice = reflect.Append(newSlice, reflect.ValueOf(elem).Convert(sliceType.Elem()))
+	}
+	return newSlice, nil
+}
@@ -0,0 +1,105 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gopherjs/jquery"
+)
+
+// logRangeSteps is how many positions a LogRange slider has between min and max.
+const logRangeSteps = 1000
+
+// LogRange takes a pointer to an int or float64 value and returns a JQuery object associated with it in the
+// form of an input of range type where the position of the slider is logarithmic between min and max, plus the
+// current value. This suits values that span orders of magnitude, such as frequencies. A non-nil error is
+// returned in the event the conversion fails, including if min isn't greater than 0 or max isn't greater than
+// min. The value is clamped to min and max to position the slider.
+//
+// An int is rounded to the nearest whole number before being validated.
+func LogRange(ptr interface{}, title, id, class string, min, max float64, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.min, o.max = min, max
+	o.valid = valid
+	o.apply(opts)
+	return logRangeControl(ptr, o)
+}
+
+func logRangeControl(ptr interface{}, o options) (jquery.JQuery, error) {
+	var get func() float64
+	var set func(float64) (old, new interface{})
+	var check func(float64) interface{}
+	switch v := ptr.(type) {
+	case *int:
+		get = func() float64 {
+			return float64(*v)
+		}
+		check = func(f float64) interface{} {
+			return int(math.Floor(f + 0.5))
+		}
+		set = func(f float64) (old, new interface{}) {
+			old, *v = *v, int(math.Floor(f+0.5))
+			return old, *v
+		}
+	case *float64:
+		get = func() float64 {
+			return *v
+		}
+		check = func(f float64) interface{} {
+			return f
+		}
+		set = func(f float64) (old, new interface{}) {
+			old, *v = *v, f
+			return old, *v
+		}
+	default:
+		return jq(), fmt.Errorf("ptr should be a pointer to an int or float64, got %T instead", ptr)
+	}
+	min, max := o.min, o.max
+	if !(min > 0) || !(max > min) {
+		return jq(), fmt.Errorf("logrange requires 0 < min < max, got min %v and max %v", min, max)
+	}
+	// toPos and fromPos convert between values and slider positions
+	scale := math.Log(max / min)
+	toPos := func(f float64) int {
+		f = math.Max(min, math.Min(max, f))
+		return int(math.Floor(math.Log(f/min)/scale*logRangeSteps + 0.5))
+	}
+	fromPos := func(pos int) float64 {
+		return min * math.Exp(float64(pos)/logRangeSteps*scale)
+	}
+
+	j := jq("<span>").AddClass(o.typeClass("logrange")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	input := jqInput("<input>").SetAttr("type", "range")
+	input.SetAttr("min", 0).SetAttr("max", logRangeSteps).SetAttr("value", toPos(get()))
+	output := jq("<output>").SetText(strconv.FormatFloat(get(), 'g', 4, 64))
+	input.Call(jquery.CHANGE, func(event jquery.Event) {
+		pos, e := strconv.Atoi(event.Target.Get("value").String())
+		if e != nil {
+			input.SetVal(toPos(get()))
+			return
+		}
+		f := fromPos(pos)
+		if o.valid != nil && !o.valid.Validate(check(f)) {
+			input.SetVal(toPos(get()))
+			return
+		}
+		var old, new interface{}
+		if !o.store(input, check(f), func() {
+			old, new = set(f)
+		}) {
+			return
+		}
+		output.SetText(strconv.FormatFloat(get(), 'g', 4, 64))
+		o.didChange(input, old, new)
+	})
+	onRefresh(j, func() {
+		input.SetVal(toPos(get()))
+		output.SetText(strconv.FormatFloat(get(), 'g', 4, 64))
+	})
+	j.Append(input).Append(output)
+	return j, nil
+}
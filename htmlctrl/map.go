@@ -0,0 +1,173 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/gopherjs/jquery"
+)
+
+// Map takes a pointer to a map and returns a JQuery object associated with it. A non-nil error is returned in the
+// event the conversion fails. Each entry is shown as its key followed by a control for its value, with a button
+// for deleting it. If the key type is a string, int, float64, or bool there is also an input for the key of a new
+// entry and a button for adding it. The map's value type must be among those supported by this package or a
+// pointer to one.
+//
+// Entries are shown in order of their keys so that they stay in place when the map is shown again.
+//
+// Values that are structs are shown as a struct control under their key. Since a value in a map can't be changed
+// in place, each one is edited as a copy that is stored back into the map whenever it changes.
+//
+// min, max, step, and valid will be applied if the map's value type supports it.
+func Map(mapPtr interface{}, title, id, class string, min, max, step float64, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.min, o.max, o.step = min, max, step
+	o.valid = valid
+	o.apply(opts)
+	return mapControl(mapPtr, o)
+}
+
+func mapControl(mapPtr interface{}, o options) (jquery.JQuery, error) {
+	t, v := reflect.TypeOf(mapPtr), reflect.ValueOf(mapPtr)
+	if t.Kind() != reflect.Ptr {
+		return jq(), fmt.Errorf("mapPtr should be a pointer, got %s instead", t.Kind())
+	}
+	if t.Elem().Kind() != reflect.Map {
+		return jq(), fmt.Errorf("mapPtr should be a pointer to map, got pointer to %s instead", t.Elem().Kind())
+	}
+	mapType, mapValue := t.Elem(), v.Elem()
+
+	j := jq("<div>").AddClass(o.typeClass("map")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+
+	eo := o.elemOptions()
+	var populate func() error
+	// rebuild redoes every entry, which is simplest when entries are added or deleted
+	rebuild := func() {
+		j.Empty()
+		if e := populate(); e != nil {
+			panic(e)
+		}
+		setTabIndex(j, o)
+	}
+	populate = func() error {
+		for _, key := range sortedKeys(mapValue) {
+			key := key
+			ko := eo
+			ko.set = func(val reflect.Value) {
+				mapValue.SetMapIndex(key, val)
+			}
+			if o.autoID {
+				ko.id = fmt.Sprintf("%s-%v", o.id, key.Interface())
+			}
+			ko.path = fmt.Sprintf("%s[%v]", o.path, key.Interface())
+			jv, e := render(mapValue.MapIndex(key), ko)
+			if e != nil {
+				return e
+			}
+			delBtn := jqInput("<button>").SetText(o.sliceText().SliceDel)
+			delBtn.Call(jquery.CLICK, func() {
+				old := copyMap(mapValue)
+				if !ko.store(j, nil, func() {
+					mapValue.SetMapIndex(key, reflect.Value{})
+				}) {
+					return
+				}
+				o.didChange(j, old, mapValue.Interface())
+				rebuild()
+			})
+			row := jq("<div>").AddClass(ClassPrefix + "-map-entry")
+			row.Append(jq("<label>").SetText(fmt.Sprint(key.Interface()))).Append(jv).Append(delBtn)
+			j.Append(row)
+		}
+
+		switch mapType.Key().Kind() {
+		case reflect.String, reflect.Int, reflect.Float64, reflect.Bool:
+		default:
+			// There's no way to type in other keys
+			return nil
+		}
+		keyInput := jqInput("<input>").SetAttr("type", "text").AddClass(ClassPrefix + "-map-key")
+		addBtn := jqInput("<button>").SetText(o.sliceText().SliceAdd)
+		addBtn.Call(jquery.CLICK, func() {
+			k, e := parseValue(keyInput.Val(), mapType.Key().Kind(), defaultOptions())
+			if e != nil {
+				return
+			}
+			key := reflect.ValueOf(k).Convert(mapType.Key())
+			if !mapValue.IsNil() && mapValue.MapIndex(key).IsValid() {
+				// Already there
+				return
+			}
+			old := copyMap(mapValue)
+			elemType := mapType.Elem()
+			newElem := reflect.New(elemType).Elem()
+			if elemType.Kind() == reflect.Ptr {
+				newElem = reflect.New(elemType.Elem())
+			}
+			ko := eo
+			ko.path = fmt.Sprintf("%s[%v]", o.path, key.Interface())
+			if !ko.store(j, newElem.Interface(), func() {
+				if mapValue.IsNil() {
+					mapValue.Set(reflect.MakeMap(mapType))
+				}
+				mapValue.SetMapIndex(key, newElem)
+			}) {
+				return
+			}
+			o.didChange(j, old, mapValue.Interface())
+			rebuild()
+		})
+		j.Append(jq("<div>").AddClass(ClassPrefix + "-map-new").Append(keyInput).Append(addBtn))
+		return nil
+	}
+
+	if e := populate(); e != nil {
+		return jq(), e
+	}
+	// Entries may have been added or deleted too, and values that are copies have to be taken from the map again
+	onRefresh(j, rebuild)
+	return j, nil
+}
+
+// sortedKeys returns the keys of the map v in order. Numbers, strings, and bools are sorted by value, anything
+// else by how fmt prints it.
+func sortedKeys(v reflect.Value) []reflect.Value {
+	keys := v.MapKeys()
+	sort.Slice(keys, func(a, b int) bool {
+		return lessValue(keys[a], keys[b])
+	})
+	return keys
+}
+
+// lessValue reports whether a sorts before b, which must have the same type. Numbers, strings, and bools are
+// compared by value and anything else by how it's printed.
+func lessValue(a, b reflect.Value) bool {
+	switch a.Kind() {
+	case reflect.String:
+		return a.String() < b.String()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return a.Int() < b.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return a.Uint() < b.Uint()
+	case reflect.Float32, reflect.Float64:
+		return a.Float() < b.Float()
+	case reflect.Bool:
+		return !a.Bool() && b.Bool()
+	}
+	return fmt.Sprint(a.Interface()) < fmt.Sprint(b.Interface())
+}
+
+// copyMap returns a copy of the map v that can't be changed through v.
+func copyMap(v reflect.Value) interface{} {
+	if v.IsNil() {
+		return v.Interface()
+	}
+	cp := reflect.MakeMap(v.Type())
+	for _, key := range v.MapKeys() {
+		cp.SetMapIndex(key, v.MapIndex(key))
+	}
+	return cp.Interface()
+}
@@ -0,0 +1,17 @@
+package htmlctrl
+
+import (
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// ControlMarshaler is implemented by types that create their own control. Struct, Slice, and anything else that
+// converts values recursively will call MarshalControl instead of converting the value by its type. The title,
+// id, and class are the html attributes the value would otherwise have been given. The control is responsible
+// for storing changes into the value itself.
+type ControlMarshaler interface {
+	MarshalControl(title, id, class string) (jquery.JQuery, error)
+}
+
+var controlMarshalerType = reflect.TypeOf((*ControlMarshaler)(nil)).Elem()
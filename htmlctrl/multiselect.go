@@ -0,0 +1,99 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// MultiSelect takes a pointer to a slice of strings and returns a JQuery object associated with it in the form
+// of a select tag that allows multiple selections. A non-nil error is returned in the event the conversion
+// fails, including if an element isn't among choices. The selected options, in the order of choices, become the
+// contents of the slice. This suits slices whose elements come from a small, known set.
+//
+// Every selected option must pass valid, otherwise the whole change is reverted.
+func MultiSelect(slicePtr interface{}, choices []string, title, id, class string, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.choices = choices
+	o.valid = valid
+	o.apply(opts)
+	return multiSelectControl(slicePtr, o)
+}
+
+func multiSelectControl(slicePtr interface{}, o options) (jquery.JQuery, error) {
+	t, v := reflect.TypeOf(slicePtr), reflect.ValueOf(slicePtr)
+	if t.Kind() != reflect.Ptr {
+		return jq(), fmt.Errorf("slicePtr should be a pointer, got %s instead", t.Kind())
+	}
+	if t.Elem().Kind() != reflect.Slice || t.Elem().Elem().Kind() != reflect.String {
+		return jq(), fmt.Errorf("slicePtr should be a pointer to slice of strings, got pointer to %s instead", t.Elem())
+	}
+	if len(o.choices) == 0 {
+		return jq(), fmt.Errorf("multiselect requires choices")
+	}
+	sliceValue := v.Elem()
+	for i := 0; i < sliceValue.Len(); i++ {
+		if indexOf(o.choices, sliceValue.Index(i).String()) == -1 {
+			return jq(), fmt.Errorf("element %d '%s' is not among valid choices", i, sliceValue.Index(i).String())
+		}
+	}
+
+	j := jqInput("<select>").AddClass(o.typeClass("multiselect")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetAttr("multiple", true)
+	for _, c := range o.choices {
+		j.Append(jq("<option>").SetAttr("value", c).SetText(c))
+	}
+	// update selects the options that are in the slice
+	update := func() {
+		options := j.Get(0).Get("options")
+		for i := 0; i < options.Length(); i++ {
+			selected := false
+			for k := 0; k < sliceValue.Len(); k++ {
+				selected = selected || sliceValue.Index(k).String() == o.choices[i]
+			}
+			options.Index(i).Set("selected", selected)
+		}
+	}
+	update()
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		newSlice := reflect.MakeSlice(sliceValue.Type(), 0, 0)
+		options := event.Target.Get("options")
+		for i := 0; i < options.Length(); i++ {
+			if !options.Index(i).Get("selected").Bool() {
+				continue
+			}
+			if o.valid != nil && !o.valid.Validate(o.choices[i]) {
+				o.reject(j, update)
+				return
+			}
+			newSlice = reflect.Append(newSlice, reflect.ValueOf(o.choices[i]).Convert(sliceValue.Type().Elem()))
+		}
+		if o.sliceValid != nil && !o.sliceValid.Validate(newSlice.Interface()) {
+			o.reject(j, update)
+			return
+		}
+		j.RemoveClass(ClassPrefix + "-invalid")
+		old := copySlice(sliceValue)
+		if !o.store(j, newSlice.Interface(), func() {
+			sliceValue.Set(newSlice)
+		}) {
+			return
+		}
+		o.didChange(j, old, newSlice.Interface())
+	})
+	onRefresh(j, update)
+	return j, nil
+}
+
+// indexOf returns the index of s in list, or -1 if it isn't there.
+func indexOf(list []string, s string) int {
+	for i, l := range list {
+		if l == s {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,46 @@
+package htmlctrl
+
+import (
+	"reflect"
+)
+
+// optional is a pointer to a number that may be nil, which is shown as an empty input. The control edits target,
+// which ptr points to whenever it isn't nil. All of the methods can be called on a nil *optional, which is a
+// number that is always there.
+type optional struct {
+	ptr, target reflect.Value
+}
+
+// newOptional returns the optional for ptr, a settable pointer.
+func newOptional(ptr reflect.Value) *optional {
+	target := ptr
+	if ptr.IsNil() {
+		target = reflect.New(ptr.Type().Elem())
+	}
+	return &optional{ptr, target}
+}
+
+// isNil returns true if the pointer is nil.
+func (n *optional) isNil() bool {
+	return n != nil && n.ptr.IsNil()
+}
+
+// setNil sets the pointer to nil.
+func (n *optional) setNil() {
+	n.ptr.Set(reflect.Zero(n.ptr.Type()))
+}
+
+// set points the pointer at target, if it's nil, after a number has been stored there.
+func (n *optional) set() {
+	if n.isNil() {
+		n.ptr.Set(n.target)
+	}
+}
+
+// value returns nil if the pointer is nil or else v, the number it points to.
+func (n *optional) value(v interface{}) interface{} {
+	if n.isNil() {
+		return nil
+	}
+	return v
+}
@@ -0,0 +1,725 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gopherjs/jquery"
+)
+
+// options holds everything that affects how a value is converted. The exported functions fill it in from their
+// arguments and Struct fills it in from the field's tags.
+type options struct {
+	title, id, class string
+	// tabIndex becomes the "tabindex" html attribute of the inputs of a field. Empty means they keep their natural
+	// order.
+	tabIndex string
+	// label is the name of the value, such as the name of the struct field holding it.
+	label          string
+	choices        []string
+	widget         string
+	min, max, step float64
+	valid          Validator
+	// minTime and maxTime limit a time.Time. The zero time means there is no limit.
+	minTime, maxTime time.Time
+	// throttle limits how often numeric controls commit while they are being changed. 0 means they only commit
+	// once the change is complete.
+	throttle time.Duration
+	// enter makes numeric controls commit when Enter is pressed. If it's "next" the next control also gets focus.
+	enter string
+	// commit is which event stores the value of a string or number: "change", "blur", or "input". "" means
+	// "change".
+	commit string
+	// changed is called after the control stores a new value. Containers pass it on to their elements so that
+	// it's called for changes anywhere within them.
+	changed func()
+	// labels is the text used for things like buttons.
+	labels Labels
+	// addText and delText replace the text of the add and delete buttons of a slice or map, but not those of any
+	// within it. "" means the labels are used.
+	addText, delText string
+	// emptyText is shown in place of the elements of a slice while it's empty. "" means nothing is shown.
+	emptyText string
+	// set stores a value back into its container. It's needed to convert values that aren't addressable, such as
+	// those in a map or interface, since the control can only edit a copy of them.
+	set func(reflect.Value)
+	// csv renders a slice as a single text input of comma separated values.
+	csv bool
+	// fieldset renders a struct as a fieldset with a legend instead of a div.
+	fieldset bool
+	// render is how a struct field of a struct is placed within it, "inline" or "section". "" means it's placed
+	// like any other field.
+	render string
+	// fields are the names of the fields of a struct to convert, in order. nil means all of them.
+	fields []string
+	// inline lays out the fields of a struct in a row instead of stacking them.
+	inline bool
+	// sliceValid is given the whole slice after every change to it, including changes to its elements. If it
+	// returns false the change is reverted.
+	sliceValid Validator
+	// addValid disables the add button of a slice while any of its elements are invalid.
+	addValid bool
+	// table shows a slice of structs as a table with a row for each element.
+	table bool
+	// cells renders a struct as a table row with a cell for each field, for an element of a table.
+	cells bool
+	// draggable lets the elements of a slice be reordered by dragging them.
+	draggable bool
+	// ordered shows a slice as a numbered list.
+	ordered bool
+	// duplicate gives each element of a slice a button that appends a copy of it.
+	duplicate bool
+	// minLen and maxLen limit the number of characters in a string. 0 means there is no limit.
+	minLen, maxLen int
+	// counter shows the number of characters in a string next to it.
+	counter bool
+	// autoID gives every control within a container without an id of its own one made from the container's id
+	// and the path to the control, e.g. "form-Items-0".
+	autoID bool
+	// copyJSON adds a button to a struct that copies it to the clipboard as JSON.
+	copyJSON bool
+	// path is where the value is within the top-level control, e.g. "Items[0].Name".
+	path string
+	// beforeRender and afterRender are called around the conversion of each value within a container.
+	beforeRender func(path string)
+	afterRender  func(path string, j jquery.JQuery)
+	// optional is set when the number being converted is pointed to by a pointer that may be nil.
+	optional *optional
+	// ready is called with a struct's control once it and everything within it has been built.
+	ready func(jquery.JQuery)
+	// actions adds a button to a struct for each of its methods that takes no arguments.
+	actions bool
+	// prototype is deep copied into each element that is added to a slice, instead of starting with the zero
+	// value.
+	prototype interface{}
+	// noNative leaves out the html attributes that the browser validates on its own, like min and max, so that
+	// only this package reports invalid values.
+	noNative bool
+	// noTypeClass leaves out the classes that say what kind of control an element is, like ClassPrefix-int.
+	noTypeClass bool
+	// soft leaves invalid entries in place, marked as invalid, instead of reverting them.
+	soft bool
+	// strict returns an error for the fields of a struct whose types aren't supported instead of leaving them out.
+	strict bool
+	// asyncValid is given each value of a string after it's stored. If it eventually fails, the value is reverted.
+	asyncValid AsyncValidator
+	// shiftStep is how many steps the arrow keys change a number by while Shift is held. 0 means Shift has no
+	// effect.
+	shiftStep float64
+	// keyFilter keeps the user from typing characters that can't be part of a number.
+	keyFilter bool
+	// decimals is the most digits a float64 may have after the decimal point. -1 means there is no limit.
+	decimals int
+	// positive and nonNegative reject numbers that aren't greater than 0, or are less than 0, regardless of min.
+	positive, nonNegative bool
+	// clear adds a button to strings, numbers, and choices that sets them to their zero value.
+	clear bool
+	// prefix and unit are shown before and after an int or float64. "" means nothing is shown.
+	prefix, unit string
+	// autocomplete becomes the "autocomplete" html attribute of string and number inputs. "" leaves it out.
+	autocomplete string
+	// multiline edits a string in a textarea.
+	multiline bool
+	// boolVals are the strings stored for checked and unchecked by a boolstring. nil means "true" and "false".
+	boolVals []string
+	// image shows a byte slice as an image preview with a file input to replace it.
+	image bool
+	// encoding is how a byte slice is shown as text, e.g. "hex".
+	encoding string
+	// setter is given each write to the bound value, which it makes by calling store. nil means writes are made
+	// directly.
+	setter func(path string, value interface{}, store func())
+	// tags are the struct tags that have already been parsed by a Template.
+	tags map[reflect.StructTag]tagOptions
+}
+
+// Option changes how a value is converted. Options are applied in order, after any other arguments.
+type Option func(*options)
+
+// WithTitle sets the "title" html attribute.
+func WithTitle(title string) Option {
+	return func(o *options) {
+		o.title = title
+	}
+}
+
+// WithID sets the "id" html attribute.
+func WithID(id string) Option {
+	return func(o *options) {
+		o.id = id
+	}
+}
+
+// WithClass sets the "class" html attribute.
+func WithClass(class string) Option {
+	return func(o *options) {
+		o.class = class
+	}
+}
+
+// WithRange sets the minimum and maximum value of a number. NaN means there is no limit.
+func WithRange(min, max float64) Option {
+	return func(o *options) {
+		o.min, o.max = min, max
+	}
+}
+
+// WithStep sets how much the up and down buttons change a number by.
+func WithStep(step float64) Option {
+	return func(o *options) {
+		o.step = step
+	}
+}
+
+// WithValidator sets the validator that changes must pass.
+func WithValidator(valid Validator) Option {
+	return func(o *options) {
+		o.valid = valid
+	}
+}
+
+// WithAsyncValidator sets a validator whose result arrives later, such as from a server, for a string or the
+// strings in a slice. See AsyncValidator for how values that fail are handled.
+func WithAsyncValidator(valid AsyncValidator) Option {
+	return func(o *options) {
+		o.asyncValid = valid
+	}
+}
+
+// WithSliceValidator sets the validator that a slice as a whole must pass. It's given the new slice whenever an
+// element is added, deleted, or changed, and if it returns false the change is reverted. This is the same as the
+// slicevalid tag.
+func WithSliceValidator(valid Validator) Option {
+	return func(o *options) {
+		o.sliceValid = valid
+	}
+}
+
+// WithTable shows a slice of structs as a table with a header row of field names and a row of controls for each
+// element, instead of a list. This is the same as the table tag.
+func WithTable() Option {
+	return func(o *options) {
+		o.table = true
+	}
+}
+
+// WithDraggable lets the elements of a slice be reordered by dragging one and dropping it onto another, which
+// moves it to that position. This is the same as the draggable widget.
+func WithDraggable() Option {
+	return func(o *options) {
+		o.draggable = true
+	}
+}
+
+// WithOrdered shows a slice as a numbered list, i.e. an ol tag instead of a ul tag. This is the same as the
+// ordered tag.
+func WithOrdered() Option {
+	return func(o *options) {
+		o.ordered = true
+	}
+}
+
+// WithDuplicate gives each element of a slice a button that appends a deep copy of it to the slice. This is the
+// same as the duplicate tag.
+func WithDuplicate() Option {
+	return func(o *options) {
+		o.duplicate = true
+	}
+}
+
+// WithRenderHooks sets functions that are called before and after each value within the control is converted,
+// such as the fields of a struct and the elements of a slice, but not the control itself. They're given the path
+// to the value, e.g. "Items[0].Name", and after gets the finished JQuery object so it can add things like classes
+// or data. Either may be nil.
+func WithRenderHooks(before func(path string), after func(path string, j jquery.JQuery)) Option {
+	return func(o *options) {
+		o.beforeRender, o.afterRender = before, after
+	}
+}
+
+// WithOnReady sets a function that is called with the control of a struct once everything within it has been
+// built, which is a good time to do things like attach tooltips. It's only called for the top-level struct, and
+// again whenever the controls are rebuilt after an action (see StructWithActions).
+func WithOnReady(ready func(root jquery.JQuery)) Option {
+	return func(o *options) {
+		o.ready = ready
+	}
+}
+
+// WithClear adds a button after strings, ints, float64s, and choices that clears them, i.e. sets them to the
+// empty string, 0, or the first choice. This is the same as the clear tag.
+func WithClear() Option {
+	return func(o *options) {
+		o.clear = true
+	}
+}
+
+// WithSliceText sets the text of the add and delete buttons of a slice or map, leaving the labels of anything
+// within it alone. An empty string leaves that button's text alone. This is the same as the addtext and deltext
+// tags.
+func WithSliceText(add, del string) Option {
+	return func(o *options) {
+		o.addText, o.delText = add, del
+	}
+}
+
+// WithUnit shows prefix before an int or float64 and unit after it, such as "$" or "MB", to say what the number
+// means. They're only for display, the number is stored as usual. The input and the text are in a span with the
+// ClassPrefix-unit-wrap class, with the text in spans with the ClassPrefix-unit-prefix and ClassPrefix-unit
+// classes. An empty string leaves that one out. This is the same as the prefix and unit tags.
+func WithUnit(prefix, unit string) Option {
+	return func(o *options) {
+		o.prefix, o.unit = prefix, unit
+	}
+}
+
+// WithAutocomplete sets the "autocomplete" html attribute of string and number inputs, such as "off" for
+// something sensitive or "email" to let the browser fill in an email address. This is the same as the
+// autocomplete tag.
+func WithAutocomplete(value string) Option {
+	return func(o *options) {
+		o.autocomplete = value
+	}
+}
+
+// WithEmptyText shows text, such as "No items yet", in place of the elements of a slice while it has none. It's
+// in a list item, or a table row, with the ClassPrefix-slice-empty class. This is the same as the emptytext tag.
+func WithEmptyText(text string) Option {
+	return func(o *options) {
+		o.emptyText = text
+	}
+}
+
+// WithSetter routes every write that the control and everything within it make to the bound value through
+// setter, e.g. so that a lock can be held around it. setter is given the path to the value being written, as for
+// WithRenderHooks ("" for the control's own value), and the new value, and must call store exactly once to make
+// the write. Adding an entry to a map or deleting one is given the path to the entry and the new value, nil when
+// deleting. The change is reported to OnChange afterwards as usual.
+//
+// A value that isn't addressable, like one in a map, is edited as a copy which is then stored back into its
+// container, so only storing the copy goes through setter.
+func WithSetter(setter func(path string, value interface{}, store func())) Option {
+	return func(o *options) {
+		o.setter = setter
+	}
+}
+
+// WithLabels makes everything within the control use the given labels.
+func WithLabels(labels Labels) Option {
+	return func(o *options) {
+		o.labels = labels
+	}
+}
+
+// WithoutTypeClasses leaves out the classes that say what kind of control each element is, such as
+// ClassPrefix-int and ClassPrefix-struct, from everything within the control, for pages with their own styling.
+// The class given as an argument or by a class tag is still added, and so are the classes for states and parts of
+// a control, such as ClassPrefix-invalid and ClassPrefix-slice-add. Without the type classes the "next" mode of
+// the enter tag can't find the next control.
+func WithoutTypeClasses() Option {
+	return func(o *options) {
+		o.noTypeClass = true
+	}
+}
+
+// StrictMode makes Struct, and every struct within the control, return an error listing every exported field
+// whose type isn't supported, such as chan, instead of leaving those fields out. Other errors, such as a bad tag,
+// are returned either way, as are unsupported elements of a slice or map since they can't be left out.
+func StrictMode() Option {
+	return func(o *options) {
+		o.strict = true
+	}
+}
+
+// WithoutNativeValidation leaves out the html attributes that the browser validates on its own, such as min, max,
+// and maxlength, from everything within the control. The limits are still enforced as usual. This avoids the
+// browser's messages showing alongside reverted changes. This is the same as the nonative tag.
+func WithoutNativeValidation() Option {
+	return func(o *options) {
+		o.noNative = true
+	}
+}
+
+// WithSoftValidation leaves invalid entries into an int, float64, string, or json.RawMessage in place instead of
+// reverting them, such as a partly typed email address. The value isn't stored until it's valid, and meanwhile the
+// input has the ClassPrefix-invalid class. Use FormValid to check whether anything is invalid. This applies to
+// everything within the control and is the same as the soft tag.
+func WithSoftValidation() Option {
+	return func(o *options) {
+		o.soft = true
+	}
+}
+
+// WithShiftStep makes the up and down arrow keys change a number by multiple steps instead of one while Shift is
+// held, e.g. 10 for ten times the step. This is the same as the shiftstep tag.
+func WithShiftStep(multiple float64) Option {
+	return func(o *options) {
+		o.shiftStep = multiple
+	}
+}
+
+// WithDecimals rejects entries into a float64 with more than n digits after the decimal point, e.g. 2 for an
+// amount of money. Trailing zeros aren't counted. This is the same as the decimals tag.
+func WithDecimals(n int) Option {
+	return func(o *options) {
+		o.decimals = n
+	}
+}
+
+// WithKeyFilter keeps the user from typing characters into a number that can't be part of one. Pasting isn't
+// affected. This is the same as the keyfilter tag.
+func WithKeyFilter() Option {
+	return func(o *options) {
+		o.keyFilter = true
+	}
+}
+
+// WithPrototype makes each element added to a slice start as a deep copy of proto instead of the zero value.
+// proto should be of the slice's element type or, if the elements are pointers, the type they point to. If the
+// elements are interfaces, such as in a []interface{}, proto may be any value that implements the interface.
+func WithPrototype(proto interface{}) Option {
+	return func(o *options) {
+		o.prototype = proto
+	}
+}
+
+// WithLength sets the minimum and maximum number of characters in a string. 0 means there is no limit.
+func WithLength(min, max int) Option {
+	return func(o *options) {
+		o.minLen, o.maxLen = min, max
+	}
+}
+
+// WithThrottle makes numbers update while they are being changed, but no more than once per d. This is the same
+// as the throttle tag.
+func WithThrottle(d time.Duration) Option {
+	return func(o *options) {
+		o.throttle = d
+	}
+}
+
+// WithCommitMode chooses when strings, ints, and float64s store what the user enters. mode is one of
+//  change - When the browser reports a change, which is usually when the input loses focus. This is the default.
+//  blur - Only when the input loses focus.
+//  input - As the user types. Invalid entries are left alone until the input loses focus, when they're reverted.
+// This applies to everything within the control unless a commit tag says otherwise, and is the same as the commit
+// tag. An unknown mode is reported as an error by the conversion.
+func WithCommitMode(mode string) Option {
+	return func(o *options) {
+		o.commit = mode
+	}
+}
+
+// WithEnter makes numbers update when Enter is pressed. If next is true the next control also gets focus. This is
+// the same as the enter tag.
+func WithEnter(next bool) Option {
+	return func(o *options) {
+		o.enter = "true"
+		if next {
+			o.enter = "next"
+		}
+	}
+}
+
+func defaultOptions() options {
+	return options{
+		min:      math.NaN(),
+		max:      math.NaN(),
+		step:     math.NaN(),
+		labels:   DefaultLabels(),
+		decimals: -1,
+	}
+}
+
+// apply applies each of opts to o in order.
+func (o *options) apply(opts []Option) {
+	for _, opt := range opts {
+		opt(o)
+	}
+}
+
+// inherit copies the settings that apply to everything within a container from the container's options.
+func (o *options) inherit(parent options) {
+	o.changed = parent.changed
+	o.setter = parent.setter
+	o.tags = parent.tags
+	o.beforeRender, o.afterRender = parent.beforeRender, parent.afterRender
+	o.labels = parent.labels
+	o.autoID = parent.autoID
+	o.noNative = o.noNative || parent.noNative
+	o.noTypeClass = parent.noTypeClass
+	o.strict = parent.strict
+	if o.commit == "" {
+		o.commit = parent.commit
+	}
+	o.soft = o.soft || parent.soft
+}
+
+// typeClass returns the class that says the control is a name, e.g. ClassPrefix-int for "int", or "" if type
+// classes are left out. Adding "" has no effect.
+func (o options) typeClass(name string) string {
+	if o.noTypeClass {
+		return ""
+	}
+	return ClassPrefix + "-" + name
+}
+
+// attrOptions returns the default options with the given html attributes.
+func attrOptions(title, id, class string) options {
+	o := defaultOptions()
+	o.title, o.id, o.class = title, id, class
+	return o
+}
+
+// elemOptions returns the options that are passed on to the elements of a container. The html attributes and
+// the choice of control only apply to the container itself.
+func (o options) elemOptions() options {
+	o.title, o.id, o.class, o.tabIndex = "", "", "", ""
+	o.choices, o.widget, o.csv, o.encoding, o.image, o.table = nil, "", false, "", false, false
+	o.draggable, o.duplicate, o.ordered, o.addValid = false, false, false, false
+	o.copyJSON, o.actions, o.prototype, o.sliceValid = false, false, nil, nil
+	o.addText, o.delText, o.emptyText = "", "", ""
+	o.set, o.ready = nil, nil
+	return o
+}
+
+// sliceText returns the labels for the buttons of a slice or map, with addText and delText in place of the
+// labels they replace.
+func (o options) sliceText() Labels {
+	labels := o.labels
+	if o.addText != "" {
+		labels.SliceAdd = o.addText
+	}
+	if o.delText != "" {
+		labels.SliceDel = o.delText
+	}
+	return labels
+}
+
+// parseTag returns the options described by a struct field's tag.
+func parseTag(tag reflect.StructTag) (options, error) {
+	o := defaultOptions()
+	o.title, o.id, o.class = tag.Get("title"), tag.Get("id"), tag.Get("class")
+	if o.tabIndex = tag.Get("tabindex"); o.tabIndex != "" {
+		if _, e := strconv.Atoi(o.tabIndex); e != nil {
+			return o, fmt.Errorf("tabindex as value '%s' expected an int", o.tabIndex)
+		}
+	}
+	if choices := tag.Get("choice"); choices != "" {
+		o.choices = strings.Split(choices, ",")
+	}
+	o.widget = tag.Get("widget")
+	switch style := tag.Get("style"); style {
+	case "":
+	case "buttons":
+		if o.widget != "" && o.widget != style {
+			return o, fmt.Errorf("style '%s' conflicts with widget '%s'", style, o.widget)
+		}
+		o.widget = style
+	default:
+		return o, fmt.Errorf("unknown style '%s'", style)
+	}
+	o.addText, o.delText = tag.Get("addtext"), tag.Get("deltext")
+	o.emptyText = tag.Get("emptytext")
+	o.autocomplete = tag.Get("autocomplete")
+	o.prefix, o.unit = tag.Get("prefix"), tag.Get("unit")
+	if boolVals := tag.Get("boolvals"); boolVals != "" {
+		o.boolVals = strings.Split(boolVals, ",")
+		if len(o.boolVals) != 2 {
+			return o, fmt.Errorf("boolvals as value '%s' expected two comma separated values", boolVals)
+		}
+	}
+	o.encoding = tag.Get("encoding")
+
+	validName := tag.Get("valid")
+	valid, ok := validators[validName]
+	if validName != "" && !ok {
+		return o, fmt.Errorf("unregistered validator '%s'", validName)
+	}
+	o.valid = valid
+
+	sliceValidName := tag.Get("slicevalid")
+	sliceValid, ok := validators[sliceValidName]
+	if sliceValidName != "" && !ok {
+		return o, fmt.Errorf("unregistered validator '%s'", sliceValidName)
+	}
+	o.sliceValid = sliceValid
+
+	var e error
+	if o.min, o.minTime, e = parseBoundTag(tag, "min"); e != nil {
+		return o, e
+	}
+	if o.max, o.maxTime, e = parseBoundTag(tag, "max"); e != nil {
+		return o, e
+	}
+	if o.step, e = parseFloatTag(tag, "step"); e != nil {
+		return o, e
+	}
+	if o.min > o.max {
+		return o, fmt.Errorf("min of %v is greater than max of %v", o.min, o.max)
+	}
+	if o.step <= 0 {
+		return o, fmt.Errorf("step of %v should be greater than 0", o.step)
+	}
+
+	if o.csv, e = parseBoolTag(tag, "csv"); e != nil {
+		return o, e
+	}
+
+	if o.fieldset, e = parseBoolTag(tag, "fieldset"); e != nil {
+		return o, e
+	}
+	switch o.render = tag.Get("render"); o.render {
+	case "", "inline", "section":
+	default:
+		return o, fmt.Errorf("render as value '%s' expected 'inline' or 'section'", o.render)
+	}
+
+	if o.inline, e = parseBoolTag(tag, "inline"); e != nil {
+		return o, e
+	}
+	if o.addValid, e = parseBoolTag(tag, "addvalid"); e != nil {
+		return o, e
+	}
+
+	if o.minLen, e = parseIntTag(tag, "minlen"); e != nil {
+		return o, e
+	}
+	if o.maxLen, e = parseIntTag(tag, "maxlen"); e != nil {
+		return o, e
+	}
+	if o.minLen < 0 || o.maxLen < 0 {
+		return o, fmt.Errorf("minlen and maxlen should not be negative")
+	}
+	if o.maxLen > 0 && o.minLen > o.maxLen {
+		return o, fmt.Errorf("minlen of %d is greater than maxlen of %d", o.minLen, o.maxLen)
+	}
+	if o.counter, e = parseBoolTag(tag, "counter"); e != nil {
+		return o, e
+	}
+	if o.table, e = parseBoolTag(tag, "table"); e != nil {
+		return o, e
+	}
+	if o.ordered, e = parseBoolTag(tag, "ordered"); e != nil {
+		return o, e
+	}
+	if o.duplicate, e = parseBoolTag(tag, "duplicate"); e != nil {
+		return o, e
+	}
+	if o.copyJSON, e = parseBoolTag(tag, "copyjson"); e != nil {
+		return o, e
+	}
+	if o.image, e = parseBoolTag(tag, "image"); e != nil {
+		return o, e
+	}
+	if o.multiline, e = parseBoolTag(tag, "multiline"); e != nil {
+		return o, e
+	}
+	if o.clear, e = parseBoolTag(tag, "clear"); e != nil {
+		return o, e
+	}
+	if shiftStep := tag.Get("shiftstep"); shiftStep != "" {
+		if o.shiftStep, e = strconv.ParseFloat(shiftStep, 64); e != nil || o.shiftStep <= 0 {
+			return o, fmt.Errorf("shiftstep as value '%s' expected a number greater than 0", shiftStep)
+		}
+	}
+	if decimals := tag.Get("decimals"); decimals != "" {
+		if o.decimals, e = strconv.Atoi(decimals); e != nil || o.decimals < 0 {
+			return o, fmt.Errorf("decimals as value '%s' expected an int of at least 0", decimals)
+		}
+	}
+	if o.keyFilter, e = parseBoolTag(tag, "keyfilter"); e != nil {
+		return o, e
+	}
+	if o.positive, e = parseBoolTag(tag, "positive"); e != nil {
+		return o, e
+	}
+	if o.nonNegative, e = parseBoolTag(tag, "nonnegative"); e != nil {
+		return o, e
+	}
+	if o.noNative, e = parseBoolTag(tag, "nonative"); e != nil {
+		return o, e
+	}
+	if o.soft, e = parseBoolTag(tag, "soft"); e != nil {
+		return o, e
+	}
+
+	switch o.enter = tag.Get("enter"); o.enter {
+	case "", "true", "next":
+	default:
+		return o, fmt.Errorf("enter as value '%s' expected 'true' or 'next'", o.enter)
+	}
+
+	switch o.commit = tag.Get("commit"); o.commit {
+	case "", "change", "blur", "input":
+	default:
+		return o, fmt.Errorf("commit as value '%s' expected 'change', 'blur', or 'input'", o.commit)
+	}
+
+	if throttle := tag.Get("throttle"); throttle != "" {
+		o.throttle, e = time.ParseDuration(throttle)
+		if e != nil {
+			return o, fmt.Errorf("throttle as value '%s' expected a duration", throttle)
+		}
+	}
+	return o, nil
+}
+
+// parseBoolTag parses the bool in the named tag. false is returned if the tag is absent.
+func parseBoolTag(tag reflect.StructTag, name string) (bool, error) {
+	val := tag.Get(name)
+	if val == "" {
+		return false, nil
+	}
+	b, e := strconv.ParseBool(val)
+	if e != nil {
+		return false, fmt.Errorf("%s as value '%s' expected a bool", name, val)
+	}
+	return b, nil
+}
+
+// parseIntTag parses the int in the named tag. 0 is returned if the tag is absent.
+func parseIntTag(tag reflect.StructTag, name string) (int, error) {
+	val := tag.Get(name)
+	if val == "" {
+		return 0, nil
+	}
+	i, e := strconv.Atoi(val)
+	if e != nil {
+		return 0, fmt.Errorf("%s as value '%s' expected an int", name, val)
+	}
+	return i, nil
+}
+
+// parseBoundTag parses a min or max tag, which is either a number or a time. A time is a date like "2006-01-02",
+// optionally followed by a time of day like "T15:04", in the local time zone.
+func parseBoundTag(tag reflect.StructTag, name string) (float64, time.Time, error) {
+	f, e := parseFloatTag(tag, name)
+	if e == nil {
+		return f, time.Time{}, nil
+	}
+	val := tag.Get(name)
+	t, e := parseTime(val, time.Local)
+	if e != nil {
+		return math.NaN(), time.Time{}, fmt.Errorf("%s as value '%s' expected a number or a time like '%s'", name, val,
+			timeLayout)
+	}
+	return math.NaN(), t, nil
+}
+
+// parseFloatTag parses the number in the named tag. NaN is returned if the tag is absent.
+func parseFloatTag(tag reflect.StructTag, name string) (float64, error) {
+	val := tag.Get(name)
+	if val == "" {
+		return math.NaN(), nil
+	}
+	f, e := strconv.ParseFloat(val, 64)
+	if e != nil {
+		return math.NaN(), fmt.Errorf("%s as value '%s' expected a number", name, val)
+	}
+	return f, nil
+}
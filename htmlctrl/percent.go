@@ -0,0 +1,72 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/gopherjs/jquery"
+)
+
+// Percent takes a pointer to a float64 value between 0 and 1 and returns a JQuery object associated with it in
+// the form of a progress tag plus an input of number type holding the value as a percentage. A non-nil error is
+// returned in the event the conversion fails, including if the current value is outside of 0 to 1.
+//
+// Entries are converted back to a fraction before being validated, so valid sees the same value that is stored.
+// Anything that would be outside of 0 to 1 is reverted.
+func Percent(f *float64, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return percentControl(f, o)
+}
+
+func percentControl(f *float64, o options) (jquery.JQuery, error) {
+	if !(*f >= 0 && *f <= 1) {
+		return jq(), fmt.Errorf("value %v is outside of 0 to 1", *f)
+	}
+	j := jq("<span>").AddClass(o.typeClass("percent")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	progress := jq("<progress>").SetAttr("max", 1).SetAttr("value", *f)
+	input := jqInput("<input>").SetAttr("type", "number")
+	if !o.noNative {
+		input.SetAttr("min", 0).SetAttr("max", 100)
+	}
+	if !math.IsNaN(o.step) && !o.noNative {
+		input.SetAttr("step", formatPercent(o.step))
+	} else {
+		input.SetAttr("step", "any")
+	}
+	input.SetAttr("value", formatPercent(*f))
+	input.Call(jquery.CHANGE, func(event jquery.Event) {
+		pct, e := strconv.ParseFloat(event.Target.Get("value").String(), 64)
+		newF := pct / 100
+		isInRange := e == nil && newF >= 0 && newF <= 1
+		isToLow := !math.IsNaN(o.min) && newF < o.min
+		isToHigh := !math.IsNaN(o.max) && newF > o.max
+		if !isInRange || isToLow || isToHigh || o.valid != nil && !o.valid.Validate(newF) {
+			input.SetVal(formatPercent(*f))
+			return
+		}
+		old := *f
+		if !o.store(input, newF, func() {
+			*f = newF
+		}) {
+			return
+		}
+		input.SetVal(formatPercent(newF))
+		progress.SetProp("value", newF)
+		o.didChange(input, old, newF)
+	})
+	onRefresh(j, func() {
+		input.SetVal(formatPercent(*f))
+		progress.SetProp("value", *f)
+	})
+	j.Append(progress).Append(input).Append(jq("<span>").SetText("%"))
+	return j, nil
+}
+
+// formatPercent returns the fraction f as a percentage, without the noise from multiplying by 100.
+func formatPercent(f float64) string {
+	return strconv.FormatFloat(f*100, 'g', 12, 64)
+}
@@ -0,0 +1,34 @@
+package htmlctrl
+
+import (
+	"github.com/gopherjs/jquery"
+)
+
+// wasDisabledKey is the jQuery data key that remembers which elements were disabled before SetReadOnly.
+const wasDisabledKey = "htmlctrl-was-disabled"
+
+// SetReadOnly disables every input, select, textarea, and button that this package made within root, which should
+// be returned by this package, when ro is true and enables them again when it's false. While root is read-only
+// nothing is written back, including changes triggered from code, clear buttons, SetChoices, and the restoring of
+// a value that failed asynchronous validation. Instead the controls show the stored values again. This is useful
+// for locking a form once it's submitted. Elements that were already disabled, such as the add button of a slice
+// with invalid elements, stay disabled when root is made editable again, and inputs added to root by something
+// else are left alone. root has the ClassPrefix-readonly class while it's read-only.
+func SetReadOnly(root jquery.JQuery, ro bool) {
+	if ro == root.HasClass(ClassPrefix+"-readonly") {
+		return
+	}
+	root.ToggleClass(ClassPrefix+"-readonly", ro)
+	own := "[" + ownAttr + "]"
+	root.Find(own).Add(root.Filter(own)).Each(func(i int, elem interface{}) {
+		e := jq(elem)
+		if ro {
+			e.SetData(wasDisabledKey, e.Prop("disabled"))
+			e.SetProp("disabled", true)
+			return
+		}
+		wasDisabled, _ := e.Data(wasDisabledKey).(bool)
+		e.SetProp("disabled", wasDisabled)
+		e.RemoveData(wasDisabledKey)
+	})
+}
@@ -0,0 +1,24 @@
+package htmlctrl
+
+import (
+	"github.com/gopherjs/jquery"
+)
+
+// refreshEvent is the jQuery event that Refresh triggers on each control to have it show its value again.
+const refreshEvent = "refresh." + EventNamespace
+
+// Refresh makes the bool, int, float64, string, and choice controls within root, and root itself, show the value
+// they're bound to again. This is for when the values have been changed by something other than the controls,
+// such as another control bound to the same value through a pointer. Slices of pointers refresh their elements
+// after any of them change, so elements that point to the same value stay the same.
+func Refresh(root jquery.JQuery) {
+	root.Find("*").Add(root).Each(func(i int, elem interface{}) {
+		jq(elem).TriggerHandler(refreshEvent)
+	})
+}
+
+// onRefresh arranges for fn to be called when j is refreshed. fn should show the current value and forget any
+// earlier one.
+func onRefresh(j jquery.JQuery, fn func()) {
+	j.On(refreshEvent, fn)
+}
@@ -0,0 +1,50 @@
+package htmlctrl
+
+import (
+	"github.com/gopherjs/gopherjs/js"
+	"github.com/gopherjs/jquery"
+)
+
+// Secret is like String but the input is of password type so that the value is masked. It's accompanied by a
+// button that toggles showing the value and a button that copies it to the clipboard. The returned JQuery object
+// contains all three.
+func Secret(s *string, title, id, class string, valid Validator, opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.valid = valid
+	o.apply(opts)
+	return secretControl(s, o)
+}
+
+func secretControl(s *string, o options) (jquery.JQuery, error) {
+	j := jq("<span>").AddClass(o.typeClass("secret")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	io := o
+	io.title, io.id, io.class = "", "", ""
+	input, e := stringControl(s, io)
+	if e != nil {
+		return jq(), e
+	}
+	input.SetAttr("type", "password")
+
+	showBtn := jqInput("<button>").SetText(o.labels.SecretShow)
+	showBtn.Call(jquery.CLICK, func() {
+		if input.Attr("type") == "password" {
+			input.SetAttr("type", "text")
+			showBtn.SetText(o.labels.SecretHide)
+		} else {
+			input.SetAttr("type", "password")
+			showBtn.SetText(o.labels.SecretShow)
+		}
+	})
+	copyBtn := jqInput("<button>").SetText(o.labels.SecretCopy)
+	copyBtn.Call(jquery.CLICK, func() {
+		// The clipboard API is only available in secure contexts
+		clipboard := js.Global.Get("navigator").Get("clipboard")
+		if clipboard == js.Undefined {
+			return
+		}
+		clipboard.Call("writeText", *s)
+	})
+	j.Append(input).Append(showBtn).Append(copyBtn)
+	return j, nil
+}
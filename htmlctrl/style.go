@@ -0,0 +1,53 @@
+package htmlctrl
+
+import (
+	"strings"
+)
+
+// defaultCSS is the style sheet added by InjectDefaultCSS. Each "{p}" is replaced by ClassPrefix.
+const defaultCSS = `
+.{p}-struct { margin: 5px 0; padding: 5px 5px 5px 15px; border-left: 2px solid #ccc; }
+.{p}-struct-field { margin: 3px 0; }
+.{p}-struct-field > label { display: inline-block; min-width: 8em; margin-right: 5px; }
+.{p}-struct-heading { margin: 10px 0 5px 0; }
+.{p}-struct-inline { display: inline-block; }
+.{p}-struct-inline-field { margin-right: 10px; }
+.{p}-struct-inline-field > label { font-size: small; }
+.{p}-struct-copy-error, .{p}-struct-action-error, .{p}-confirm-error, .{p}-image-error { color: red; margin-left: 5px; }
+.{p}-slice { margin: 5px 0; padding-left: 20px; }
+.{p}-slice > li { margin: 3px 0; }
+.{p}-slice button { margin-left: 5px; }
+.{p}-slice-add { list-style: none; }
+.{p}-slice-empty { list-style: none; font-style: italic; color: #777; }
+.{p}-slice-sort { cursor: pointer; }
+.{p}-slice-sort-asc::after { content: " \25B2"; }
+.{p}-slice-sort-desc::after { content: " \25BC"; }
+.{p}-slice-drag { cursor: move; }
+.{p}-map-entry { margin: 3px 0; }
+.{p}-map-entry > label { margin-right: 5px; }
+.{p}-choice-filter { display: inline-block; position: relative; }
+.{p}-choice-filter-list { position: absolute; z-index: 1; margin: 0; padding: 0; max-height: 200px;
+	overflow-y: auto; list-style: none; background: white; border: 1px solid grey; }
+.{p}-radio { display: inline-block; }
+.{p}-derived { font-style: italic; }
+.{p}-code { font-family: monospace; }
+.{p}-string-counter { font-size: small; margin-left: 5px; }
+.{p}-string-counter-invalid { color: red; }
+.{p}-image-preview { display: block; max-width: 100px; max-height: 100px; }
+.{p}-bool-selected { font-weight: bold; }
+.{p}-invalid { border-color: red; outline-color: red; }
+.{p}-pending { border-color: orange; }
+`
+
+// InjectDefaultCSS adds a style tag to the head of the page with some basic styling for the classes used by this
+// package, such as indenting structs, laying out slices, and marking invalid values, so that controls look
+// reasonable without a style sheet of their own. It uses the current ClassPrefix. Calling it again does nothing,
+// and it isn't called unless asked for, so pages with their own styling aren't affected.
+func InjectDefaultCSS() {
+	id := ClassPrefix + "-default-css"
+	if jq("#"+id).Length > 0 {
+		return
+	}
+	css := strings.Replace(defaultCSS, "{p}", ClassPrefix, -1)
+	jq("<style>").SetAttr("id", id).SetText(css).AppendTo(jq("head"))
+}
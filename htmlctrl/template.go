@@ -0,0 +1,88 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// Template converts any number of values of the same struct type, such as an address form that appears in several
+// places. The struct tags of the type, and of the structs within it, are parsed once when the Template is made
+// rather than every time a control is. Make one with NewTemplate.
+type Template struct {
+	structType reflect.Type
+	o          options
+}
+
+// Control is a control made by a Template.
+type Control struct {
+	// JQuery is the control, in the same form that Struct returns.
+	JQuery jquery.JQuery
+	// Ptr is the pointer to the struct that the control is bound to.
+	Ptr interface{}
+}
+
+// tagOptions are the results of parsing a struct tag.
+type tagOptions struct {
+	o options
+	e error
+}
+
+// NewTemplate returns a Template for structs of type structType, which may also be a pointer to a struct type.
+// opts apply to every control made from it. Since the tags are only parsed once, a validator named in a valid tag
+// must be registered before the Template is made.
+func NewTemplate(structType reflect.Type, opts ...Option) *Template {
+	if structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	o := defaultOptions()
+	o.apply(opts)
+	o.tags = make(map[reflect.StructTag]tagOptions)
+	cacheTags(structType, o.tags, make(map[reflect.Type]bool))
+	return &Template{structType, o}
+}
+
+// Instantiate returns a Control bound to structPtr, which must be a pointer to a struct of the Template's type. A
+// non-nil error is returned in the event the conversion fails, the same as for Struct.
+func (t *Template) Instantiate(structPtr interface{}) (*Control, error) {
+	if typ := reflect.TypeOf(structPtr); typ == nil || typ.Kind() != reflect.Ptr || typ.Elem() != t.structType {
+		return nil, fmt.Errorf("structPtr should be a pointer to %s, got %T instead", t.structType, structPtr)
+	}
+	j, e := structControl(structPtr, t.o)
+	if e != nil {
+		return nil, e
+	}
+	return &Control{j, structPtr}, nil
+}
+
+// cacheTags parses the tags of every struct field reachable from typ into tags. seen holds the types that have
+// already been visited, so recursive types are only visited once.
+func cacheTags(typ reflect.Type, tags map[reflect.StructTag]tagOptions, seen map[reflect.Type]bool) {
+	if seen[typ] {
+		return
+	}
+	seen[typ] = true
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		cacheTags(typ.Elem(), tags, seen)
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if _, ok := tags[field.Tag]; !ok {
+				o, e := parseTag(field.Tag)
+				tags[field.Tag] = tagOptions{o, e}
+			}
+			cacheTags(field.Type, tags, seen)
+		}
+	}
+}
+
+// tagOptions returns the options given by the tag of a struct field, which have already been parsed if the
+// control is being made by a Template.
+func (o options) tagOptions(tag reflect.StructTag) (options, error) {
+	if parsed, ok := o.tags[tag]; ok {
+		return parsed.o, parsed.e
+	}
+	return parseTag(tag)
+}
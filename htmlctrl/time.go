@@ -0,0 +1,101 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/gopherjs/jquery"
+)
+
+var timeType = reflect.TypeOf(time.Time{})
+
+const (
+	// timeLayout is the format of the value of a datetime-local input.
+	timeLayout = "2006-01-02T15:04"
+	// dateLayout is the format of a time without the time of day, which is accepted in tags.
+	dateLayout = "2006-01-02"
+)
+
+// Time takes a pointer to a time.Time and returns a JQuery object associated with it in the form of an input of
+// datetime-local type. A non-nil error is returned in the event the conversion fails. The time is edited to the
+// minute in its own location. Clearing the input stores the zero time.
+//
+// min and max, unless they're the zero time, limit the times that can be entered. Times outside of them and
+// times that don't pass valid are reverted.
+func Time(t *time.Time, title, id, class string, min, max time.Time, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.minTime, o.maxTime = min, max
+	o.valid = valid
+	o.apply(opts)
+	return timeControl(t, o)
+}
+
+func timeControl(t *time.Time, o options) (jquery.JQuery, error) {
+	if !math.IsNaN(o.min) || !math.IsNaN(o.max) {
+		return jq(), fmt.Errorf("min and max of a time should be times, e.g. '%s'", dateLayout)
+	}
+	if !o.minTime.IsZero() && !o.maxTime.IsZero() && o.minTime.After(o.maxTime) {
+		return jq(), fmt.Errorf("min of %s is after max of %s", o.minTime, o.maxTime)
+	}
+	j := jqInput("<input>").AddClass(o.typeClass("time")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	j.SetAttr("type", "datetime-local")
+	if !o.noNative {
+		if !o.minTime.IsZero() {
+			j.SetAttr("min", o.minTime.Format(timeLayout))
+		}
+		if !o.maxTime.IsZero() {
+			j.SetAttr("max", o.maxTime.Format(timeLayout))
+		}
+	}
+	j.SetAttr("value", formatTime(*t))
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		var newT time.Time
+		text := event.Target.Get("value").String()
+		var e error
+		if text != "" {
+			newT, e = parseTime(text, t.Location())
+		}
+		// The zero time stands for no time so it isn't limited
+		isToLow := !newT.IsZero() && !o.minTime.IsZero() && newT.Before(o.minTime)
+		isToHigh := !newT.IsZero() && !o.maxTime.IsZero() && newT.After(o.maxTime)
+		if e != nil || isToLow || isToHigh || o.valid != nil && !o.valid.Validate(newT) {
+			o.reject(j, func() {
+				j.SetVal(formatTime(*t))
+			})
+			return
+		}
+		j.RemoveClass(ClassPrefix + "-invalid")
+		old := *t
+		if !o.store(j, newT, func() {
+			*t = newT
+		}) {
+			return
+		}
+		o.didChange(j, old, newT)
+	})
+	onRefresh(j, func() {
+		j.SetVal(formatTime(*t))
+	})
+	return j, nil
+}
+
+// formatTime returns t as the value of a datetime-local input, which is empty for the zero time.
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.Format(timeLayout)
+}
+
+// parseTime parses text as a time in loc. It may be a date and time of day like timeLayout or just a date.
+func parseTime(text string, loc *time.Location) (time.Time, error) {
+	t, e := time.ParseInLocation(timeLayout, text, loc)
+	if e != nil {
+		t, e = time.ParseInLocation(dateLayout, text, loc)
+	}
+	return t, e
+}
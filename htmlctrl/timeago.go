@@ -0,0 +1,99 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gopherjs/jquery"
+)
+
+// TimeAgo is the same as Time but the time is shown as text relative to now, such as "3 days ago", in a button
+// with the ClassPrefix-time-ago-text class. Clicking it replaces it with the datetime-local input of Time until
+// the input loses focus. This suits times that are mostly looked at rather than edited, such as when something
+// was created. The text comes from the TimeAgo label, or the TimeNever label for the zero time.
+//
+// The text is only worked out again when the input loses focus and on Refresh, so it doesn't change as time passes
+// by itself. Call Refresh every so often to keep it current.
+func TimeAgo(t *time.Time, title, id, class string, min, max time.Time, valid Validator,
+	opts ...Option) (jquery.JQuery, error) {
+	o := attrOptions(title, id, class)
+	o.minTime, o.maxTime = min, max
+	o.valid = valid
+	o.apply(opts)
+	return timeAgoControl(t, o)
+}
+
+func timeAgoControl(t *time.Time, o options) (jquery.JQuery, error) {
+	io := o
+	io.title, io.id, io.class, io.widget = "", "", "", ""
+	input, e := timeControl(t, io)
+	if e != nil {
+		return jq(), e
+	}
+	j := jq("<span>").AddClass(o.typeClass("time-ago")).AddClass(o.class)
+	j.SetAttr("title", o.title).SetAttr("id", o.id)
+	// A button can be clicked from the keyboard too
+	text := jqInput("<button>").SetAttr("type", "button").AddClass(ClassPrefix + "-time-ago-text")
+	ago := o.labels.TimeAgo
+	if ago == nil {
+		ago = formatAgo
+	}
+	// show replaces the input with the text, which is worked out again since time has passed
+	show := func() {
+		if t.IsZero() {
+			text.SetText(o.labels.TimeNever)
+		} else {
+			text.SetText(ago(time.Since(*t)))
+		}
+		text.SetAttr("title", formatTime(*t))
+		input.Hide()
+		text.Show()
+	}
+	show()
+	text.Call(jquery.CLICK, func() {
+		text.Hide()
+		input.Show().Focus()
+	})
+	input.Call(jquery.BLUR, show)
+	onRefresh(j, func() {
+		input.SetVal(formatTime(*t))
+		show()
+	})
+	j.Append(text).Append(input)
+	return j, nil
+}
+
+// formatAgo is the default TimeAgo label. It returns how long ago something was that happened d ago, in the
+// largest unit that fits, e.g. "3 days ago". A negative d is in the future, e.g. "in 2 hours".
+func formatAgo(d time.Duration) string {
+	future := d < 0
+	if future {
+		d = -d
+	}
+	units := []struct {
+		name string
+		size time.Duration
+	}{
+		{"year", 365 * 24 * time.Hour},
+		{"month", 30 * 24 * time.Hour},
+		{"week", 7 * 24 * time.Hour},
+		{"day", 24 * time.Hour},
+		{"hour", time.Hour},
+		{"minute", time.Minute},
+	}
+	for _, unit := range units {
+		n := int(d / unit.size)
+		if n == 0 {
+			continue
+		}
+		s := fmt.Sprintf("%d %s", n, unit.name)
+		if n != 1 {
+			s += "s"
+		}
+		if future {
+			return "in " + s
+		}
+		return s + " ago"
+	}
+	return "just now"
+}
@@ -1,5 +1,16 @@
 package htmlctrl
 
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
 var validators = make(map[string]Validator)
 
 // RegisterValidator associates a name with the validator function so that it may be referenced in a struct tag.
@@ -7,6 +18,62 @@ func RegisterValidator(name string, fn Validator) {
 	validators[name] = fn
 }
 
+var typeValidators = make(map[reflect.Kind]Validator)
+
+// RegisterTypeValidator sets the validator used for all values of the given kind that are converted by Struct or
+// Slice without a valid tag of their own. A valid tag always takes precedence. Only bool, int, float64, and
+// string values are validated. Passing a nil validator removes the one for that kind.
+func RegisterTypeValidator(kind reflect.Kind, fn Validator) {
+	if fn == nil {
+		delete(typeValidators, kind)
+		return
+	}
+	typeValidators[kind] = fn
+}
+
+// Built in validators for common cases. They can be used directly or by name in a struct tag after calling
+// RegisterBuiltinValidators.
+var (
+	// NonEmptyString accepts strings that contain something other than white space.
+	NonEmptyString Validator = ValidateString(func(s string) bool {
+		return strings.TrimSpace(s) != ""
+	})
+	// PositiveInt accepts ints greater than 0.
+	PositiveInt Validator = ValidateInt(func(i int) bool {
+		return i > 0
+	})
+	// PositiveFloat64 accepts float64s greater than 0.
+	PositiveFloat64 Validator = ValidateFloat64(func(f float64) bool {
+		return f > 0
+	})
+	// EmailString accepts strings that look like an email address, i.e. something, an @, and a domain containing
+	// a dot. It doesn't check that the address exists.
+	EmailString Validator = ValidateString(func(s string) bool {
+		at := strings.LastIndex(s, "@")
+		if at < 1 || strings.ContainsAny(s, " \t\n") {
+			return false
+		}
+		domain := s[at+1:]
+		dot := strings.LastIndex(domain, ".")
+		return dot > 0 && dot < len(domain)-1
+	})
+	// URLString accepts absolute URLs, i.e. those with a scheme and a host.
+	URLString Validator = ValidateString(func(s string) bool {
+		u, e := url.Parse(s)
+		return e == nil && u.Scheme != "" && u.Host != ""
+	})
+)
+
+// RegisterBuiltinValidators registers each of the built in validators under its own name, e.g.
+// "NonEmptyString", so that they may be referenced in a struct tag.
+func RegisterBuiltinValidators() {
+	RegisterValidator("NonEmptyString", NonEmptyString)
+	RegisterValidator("PositiveInt", PositiveInt)
+	RegisterValidator("PositiveFloat64", PositiveFloat64)
+	RegisterValidator("EmailString", EmailString)
+	RegisterValidator("URLString", URLString)
+}
+
 // Validator is used to validate changes made via html objects. The Valid function is given the requested new value
 // and should return true only when it is an acceptable value. If it returns false then the change is reverted
 type Validator interface {
@@ -21,6 +88,21 @@ func (v ValidatorFunc) Validate(i interface{}) bool {
 	return v(i)
 }
 
+// ValidatorProvider returns the Validator to use for a value at the moment it's validated. It implements the
+// Validator interface by calling the provider every time, so the rules a control follows can change after it's
+// created, e.g. to follow a config that's loaded later. If the provider is nil or returns nil every value is
+// accepted.
+type ValidatorProvider func() Validator
+
+// Validate implements the Validator interface by validating with the validator that p currently returns.
+func (p ValidatorProvider) Validate(i interface{}) bool {
+	if p == nil {
+		return true
+	}
+	v := p()
+	return v == nil || v.Validate(i)
+}
+
 // ValidateBool is a function that validates bool types.
 type ValidateBool func(bool) bool
 
@@ -52,3 +134,175 @@ type ValidateString func(string) bool
 func (v ValidateString) Validate(i interface{}) bool {
 	return v(i.(string))
 }
+
+// And returns a Validator that accepts a value only if all of valids accept it. The typed validators, such as
+// ValidateInt, can be combined as long as they are all for the same type. nil validators are ignored.
+func And(valids ...Validator) Validator {
+	return ValidatorFunc(func(i interface{}) bool {
+		for _, v := range valids {
+			if v != nil && !v.Validate(i) {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// Or returns a Validator that accepts a value if any of valids accept it. nil validators are ignored, so if they
+// are all nil then nothing is accepted.
+func Or(valids ...Validator) Validator {
+	return ValidatorFunc(func(i interface{}) bool {
+		for _, v := range valids {
+			if v != nil && v.Validate(i) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// Not returns a Validator that accepts a value only if valid doesn't. A nil valid accepts everything, as it does
+// for a control, so Not(nil) rejects everything.
+func Not(valid Validator) Validator {
+	return ValidatorFunc(func(i interface{}) bool {
+		return valid != nil && !valid.Validate(i)
+	})
+}
+
+// checkValue returns a non-nil error if v, or what it points to, fails the validator or is outside the bounds in
+// o. Only the types with a Validate* function are checked, anything else is considered valid.
+func checkValue(v reflect.Value, o options) error {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if v.Type() == timeType {
+		t := v.Interface().(time.Time)
+		if !t.IsZero() && (!o.minTime.IsZero() && t.Before(o.minTime) || !o.maxTime.IsZero() && t.After(o.maxTime)) {
+			return fmt.Errorf("time %s is out of range", t)
+		}
+		if o.valid != nil && !o.valid.Validate(t) {
+			return fmt.Errorf("time %s is not valid", t)
+		}
+		return nil
+	}
+	var val interface{}
+	switch v.Kind() {
+	case reflect.Bool:
+		val = v.Bool()
+	case reflect.Int:
+		i := int(v.Int())
+		if !math.IsNaN(o.min) && i < int(o.min) || !math.IsNaN(o.max) && i > int(o.max) || !o.signOK(float64(i)) {
+			return fmt.Errorf("value %d is out of range", i)
+		}
+		val = i
+	case reflect.Float64:
+		f := v.Float()
+		if !math.IsNaN(o.min) && f < o.min || !math.IsNaN(o.max) && f > o.max || !o.signOK(f) {
+			return fmt.Errorf("value %v is out of range", f)
+		}
+		if o.decimals >= 0 && decimalPlaces(strconv.FormatFloat(f, 'f', -1, 64)) > o.decimals {
+			return fmt.Errorf("value %v has more than %d decimals", f, o.decimals)
+		}
+		val = f
+	case reflect.String:
+		if e := checkLen(v.String(), o); e != nil {
+			return e
+		}
+		if o.choices != nil && indexOf(o.choices, v.String()) == -1 {
+			return fmt.Errorf("value '%s' is not among the choices", v.String())
+		}
+		val = v.String()
+	default:
+		return nil
+	}
+	if o.valid != nil && !o.valid.Validate(val) {
+		return fmt.Errorf("value '%v' is not valid", val)
+	}
+	return nil
+}
+
+// checkLen returns a non-nil error if s has fewer than o.minLen or more than o.maxLen characters.
+func checkLen(s string, o options) error {
+	n := utf8.RuneCountInString(s)
+	if n < o.minLen || o.maxLen > 0 && n > o.maxLen {
+		return fmt.Errorf("length %d of '%s' is out of range", n, s)
+	}
+	return nil
+}
+
+// ValidateStruct checks the exported fields of the struct that structPtr points to the same way Struct would,
+// using their valid, min, max, minlen, maxlen, choice, and slicevalid tags along with the validators registered
+// for their kinds, but without building any controls. Nested structs and the elements of slices and maps are
+// checked too. There is an error for each value that fails, starting with the path to it such as
+// "Items[2].Name". The result is nil if everything is valid.
+func ValidateStruct(structPtr interface{}) []error {
+	v := reflect.ValueOf(structPtr)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return []error{fmt.Errorf("structPtr should be a pointer to struct, got %s instead", v.Type())}
+	}
+	return validateStruct(v.Elem(), "")
+}
+
+// validateStruct returns the errors for the fields of structValue, whose paths are prefixed with path.
+func validateStruct(structValue reflect.Value, path string) []error {
+	var errs []error
+	structType := structValue.Type()
+	for i := 0; i < structType.NumField(); i++ {
+		fieldType := structType.Field(i)
+		if fieldType.PkgPath != "" {
+			continue
+		}
+		name := path + fieldType.Name
+		fo, e := parseTag(fieldType.Tag)
+		if e != nil {
+			errs = append(errs, fmt.Errorf("%s: %s", name, e))
+			continue
+		}
+		errs = append(errs, validateValue(structValue.Field(i), fo, name)...)
+	}
+	return errs
+}
+
+// validateValue returns the errors for v, which is at path, and everything within it.
+func validateValue(v reflect.Value, o options, path string) []error {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	if enum, ok := stringEnums[v.Type()]; ok && o.choices == nil {
+		o.choices = enum
+	}
+	if o.valid == nil {
+		o.valid = typeValidators[v.Kind()]
+	}
+	var errs []error
+	switch {
+	case v.Type() == timeType:
+	case v.Kind() == reflect.Struct:
+		return validateStruct(v, path+".")
+	case v.Kind() == reflect.Slice && o.encoding == "":
+		if o.sliceValid != nil && !o.sliceValid.Validate(v.Interface()) {
+			errs = append(errs, fmt.Errorf("%s: slice is not valid", path))
+		}
+		eo := o.elemOptions()
+		for i := 0; i < v.Len(); i++ {
+			errs = append(errs, validateValue(v.Index(i), eo, fmt.Sprintf("%s[%d]", path, i))...)
+		}
+		return errs
+	case v.Kind() == reflect.Map:
+		eo := o.elemOptions()
+		for _, key := range sortedKeys(v) {
+			errs = append(errs, validateValue(v.MapIndex(key), eo, fmt.Sprintf("%s[%v]", path, key.Interface()))...)
+		}
+		return errs
+	}
+	if e := checkValue(v, o); e != nil {
+		errs = append(errs, fmt.Errorf("%s: %s", path, e))
+	}
+	return errs
+}
@@ -0,0 +1,25 @@
+package htmlctrl
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gopherjs/jquery"
+)
+
+// Value takes a pointer to a value of any type supported by this package and returns a JQuery object associated
+// with it, using the same control that Struct would use for a field of that type. A non-nil error is returned
+// in the event the conversion fails, including when the type isn't supported. It's useful when the type isn't
+// known ahead of time.
+func Value(ptr interface{}, title, id, class string, opts ...Option) (jquery.JQuery, error) {
+	v := reflect.ValueOf(ptr)
+	if v.Kind() != reflect.Ptr {
+		return jq(), fmt.Errorf("ptr should be a pointer, got %s instead", v.Kind())
+	}
+	if v.IsNil() {
+		return jq(), fmt.Errorf("ptr should not be nil")
+	}
+	o := attrOptions(title, id, class)
+	o.apply(opts)
+	return convert(v.Elem(), o)
+}
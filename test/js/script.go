@@ -1,8 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"math"
+	"reflect"
+	"strings"
+	"time"
 
 	"github.com/Bredgren/gohtmlctrl/htmlctrl"
 	"github.com/gopherjs/gopherjs/js"
@@ -35,9 +39,85 @@ func onBodyLoad() {
 		testInt,
 		testFloat64,
 		testString,
+		testStringCounter,
+		testPasswordConfirm,
 		testChoice,
+		testSetChoices,
+		testLinkChoices,
+		testStringEnum,
+		testMultiSelect,
+		testAsyncValidator,
+		testDuplicate,
+		testBoolString,
+		testClear,
+		testSliceTable,
+		testSliceTableSort,
+		testOptionalNumbers,
+		testCode,
+		testChoiceFilter,
 		testSlices,
+		testSliceCSV,
+		testBytes,
+		testImage,
+		testRawJSON,
 		testStruct,
+		testStructFields,
+		testMap,
+		testMapOfStructs,
+		testCallbacks,
+		testFormatted,
+		testErrorPaths,
+		testFindByType,
+		testBoolButtons,
+		testInterface,
+		testDerived,
+		testDescribeStruct,
+		testTypeValidator,
+		testValidatorComposition,
+		testBuiltinValidators,
+		testControlMarshaler,
+		testAutoID,
+		testRevertFocus,
+		testCopyJSON,
+		testActions,
+		testOnReady,
+		testTime,
+		testValidateStruct,
+		testRenderHooks,
+		testReadOnly,
+		testSetter,
+		testAliasedPointers,
+		testValue,
+		testOptions,
+		testNoNative,
+		testNoTypeClasses,
+		testValidatorProvider,
+		testTemplate,
+		testLogRange,
+		testSoftValidation,
+		testKeyFilter,
+		testDecimals,
+		testSign,
+		testPointerToPointer,
+		testSliceDrag,
+		testTabIndex,
+		testAccessor,
+		testChangeEvent,
+		testStrictMode,
+		testInterfaceSlice,
+		testTimeAgo,
+		testSliceEmptyText,
+		testSliceChoices,
+		testDirty,
+		testAutocomplete,
+		testInjectDefaultCSS,
+		testStructRender,
+		testUnit,
+		testCommitMode,
+		testShiftStep,
+		testPrototype,
+		testOnChange,
+		testMirror,
 	}
 	for _, fn := range funcs {
 		fn(body)
@@ -138,6 +218,8 @@ func testFloat64(body jquery.JQuery) {
 			return f != 5.5
 		})},
 		{"f3", 0, math.NaN(), math.NaN(), math.NaN(), nil},
+		{"f4", math.NaN(), math.NaN(), math.NaN(), math.NaN(), nil},
+		{"f5", math.Inf(1), -10, 10, 1, nil},
 	}
 	float64s := jq("<div>").AddClass("float64s")
 	for _, c := range cases {
@@ -149,6 +231,16 @@ func testFloat64(body jquery.JQuery) {
 		if title := j.Attr("title"); title != c.name {
 			logError(fmt.Sprintf("%s: title is %s, expected %s", c.name, title, c.name))
 		}
+		if math.IsNaN(c.f) || math.IsInf(c.f, 0) {
+			if val := j.Val(); val != "" {
+				logError(fmt.Sprintf("%s: value is '%s', expected it to be empty", c.name, val))
+			}
+			prev := c.f
+			j.SetVal("Infinity").Trigger(jquery.CHANGE)
+			if c.f != prev && !(math.IsNaN(c.f) && math.IsNaN(prev)) {
+				logError(fmt.Sprintf("%s: value is %f after entering 'Infinity', expected %f", c.name, c.f, prev))
+			}
+		}
 		float64s.Append(j)
 		c := &c
 		float64s.Append(jq("<button>").SetText("verify "+c.name).Call(jquery.CLICK, func() {
@@ -156,6 +248,50 @@ func testFloat64(body jquery.JQuery) {
 		}))
 	}
 	body.Append(float64s)
+
+	for _, widget := range []string{"", "text"} {
+		sci := struct {
+			F float64 `min:"0"`
+			T float64 `widget:"text"`
+		}{}
+		j, e := htmlctrl.Struct(&sci, "scientific", "", "")
+		if e != nil {
+			logError(fmt.Sprintf("scientific: unexpected error: %s", e))
+		}
+		input, f := j.Find("input").Eq(0), &sci.F
+		if widget == "text" {
+			input, f = j.Find("input").Eq(1), &sci.T
+		}
+		input.SetVal("6.022e23").Trigger(jquery.CHANGE)
+		if *f != 6.022e23 {
+			logError(fmt.Sprintf("scientific %s: value is %g, expected 6.022e23", widget, *f))
+		}
+		input.SetVal("6.022e").Trigger(jquery.CHANGE)
+		if *f != 6.022e23 {
+			logError(fmt.Sprintf("scientific %s: value is %g after invalid input, expected 6.022e23", widget, *f))
+		}
+	}
+	htmlctrl.DecimalSeparator = ","
+	comma := 0.25
+	j, e := htmlctrl.Float64(&comma, "comma", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("comma: unexpected error: %s", e))
+	}
+	if val := j.Val(); val != "0,25" {
+		logError(fmt.Sprintf("comma: value is '%s', expected '0,25'", val))
+	}
+	j.SetVal("1,5").Trigger(jquery.CHANGE)
+	if comma != 1.5 {
+		logError(fmt.Sprintf("comma: value is %v after entering '1,5', expected 1.5", comma))
+	}
+	htmlctrl.DecimalSeparator = "."
+
+	negZero := 1.0
+	j, _ = htmlctrl.Float64(&negZero, "negative zero", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	j.SetVal("-0").Trigger(jquery.CHANGE)
+	if negZero != 0 || math.Signbit(negZero) {
+		logError(fmt.Sprintf("negative zero: value is %v after entering '-0', expected 0", negZero))
+	}
 	logInfo("end testFloat64")
 }
 
@@ -194,6 +330,70 @@ func testString(body jquery.JQuery) {
 	logInfo("end testString")
 }
 
+func testStringCounter(body jquery.JQuery) {
+	logInfo("begin testStringCounter")
+	s := "hello"
+	j, e := htmlctrl.StringCounter(&s, "counter", "", "", 2, 8, nil)
+	if e != nil {
+		logError(fmt.Sprintf("counter: unexpected error: %s", e))
+	}
+	body.Append(j)
+	input, counter := j.Find("input"), j.Find(".go-string-counter")
+	if text := counter.Text(); text != "5/8" {
+		logError(fmt.Sprintf("counter: text is '%s', expected '5/8'", text))
+	}
+	input.SetVal("hello world").Trigger("input")
+	if text := counter.Text(); text != "11/8" {
+		logError(fmt.Sprintf("counter: text is '%s' while typing, expected '11/8'", text))
+	}
+	if !counter.HasClass("go-string-counter-invalid") {
+		logError("counter: expected invalid class while over maxlen")
+	}
+	input.Trigger(jquery.CHANGE)
+	if s != "hello" {
+		logError(fmt.Sprintf("counter: value is '%s', expected change over maxlen to be rejected", s))
+	}
+	if text := counter.Text(); text != "5/8" {
+		logError(fmt.Sprintf("counter: text is '%s' after rejected change, expected '5/8'", text))
+	}
+	input.SetVal("hi").Trigger("input").Trigger(jquery.CHANGE)
+	if s != "hi" || counter.HasClass("go-string-counter-invalid") {
+		logError(fmt.Sprintf("counter: value is '%s', expected 'hi' and a valid counter", s))
+	}
+	logInfo("end testStringCounter")
+}
+
+func testPasswordConfirm(body jquery.JQuery) {
+	logInfo("begin testPasswordConfirm")
+	password, confirm := "", ""
+	jp, e := htmlctrl.Secret(&password, "password", "", "", nil)
+	if e != nil {
+		logError(fmt.Sprintf("password: unexpected error: %s", e))
+	}
+	jc, e := htmlctrl.PasswordConfirm(&confirm, &password, "confirm", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("confirm: unexpected error: %s", e))
+	}
+	body.Append(jp).Append(jc)
+	jp.Find("input").SetVal("hunter2").Trigger(jquery.CHANGE)
+	input := jc.Find("input")
+	input.SetVal("hunter3").Trigger(jquery.CHANGE)
+	if confirm != "" || input.Val() != "" {
+		logError(fmt.Sprintf("confirm: value is '%s', expected a mismatch to be reverted", confirm))
+	}
+	if msg := jc.Find(".go-confirm-error").Text(); msg != "Doesn't match" {
+		logError(fmt.Sprintf("confirm: message is '%s', expected \"Doesn't match\"", msg))
+	}
+	input.SetVal("hunter2").Trigger(jquery.CHANGE)
+	if confirm != "hunter2" {
+		logError(fmt.Sprintf("confirm: value is '%s', expected a match to be accepted", confirm))
+	}
+	if msg := jc.Find(".go-confirm-error").Text(); msg != "" {
+		logError(fmt.Sprintf("confirm: message is '%s' after a match, expected none", msg))
+	}
+	logInfo("end testPasswordConfirm")
+}
+
 func testChoice(body jquery.JQuery) {
 	logInfo("begin testChoice")
 	opts := []string{
@@ -235,6 +435,656 @@ func testChoice(body jquery.JQuery) {
 	logInfo("end testChoice")
 }
 
+func testSetChoices(body jquery.JQuery) {
+	logInfo("begin testSetChoices")
+	s := "b"
+	j, e := htmlctrl.Choice(&s, []string{"a", "b", "c"}, "set choices", "", "", nil)
+	if e != nil {
+		logError(fmt.Sprintf("set choices: unexpected error: %s", e))
+	}
+	body.Append(j)
+	var changes []interface{}
+	htmlctrl.OnChange(j, func(old, new interface{}) {
+		changes = append(changes, new)
+	})
+	if e := htmlctrl.SetChoices(j, []string{"b", "d"}); e != nil {
+		logError(fmt.Sprintf("set choices: unexpected error: %s", e))
+	}
+	if s != "b" || j.Val() != "b" || j.Find("option").Length != 2 {
+		logError(fmt.Sprintf("set choices: value is '%s' with %d options, expected 'b' to be kept with 2", s,
+			j.Find("option").Length))
+	}
+	if e := htmlctrl.SetChoices(j, []string{"x", "y"}); e != nil {
+		logError(fmt.Sprintf("set choices: unexpected error: %s", e))
+	}
+	if s != "x" || j.Val() != "x" {
+		logError(fmt.Sprintf("set choices: value is '%s', expected 'x' after 'b' was removed", s))
+	}
+	if len(changes) != 1 || changes[0] != "x" {
+		logError(fmt.Sprintf("set choices: changes were %v, expected [x]", changes))
+	}
+	j.SetVal("y").Trigger(jquery.CHANGE)
+	if s != "y" {
+		logError(fmt.Sprintf("set choices: value is '%s', expected new choice 'y' to be selectable", s))
+	}
+	if e := htmlctrl.SetChoices(j, nil); e == nil {
+		logError("set choices: expected error for no choices")
+	}
+	if e := htmlctrl.SetChoices(jq("<select>"), []string{"a"}); e == nil {
+		logError("set choices: expected error for a select that isn't a Choice")
+	}
+	logInfo("end testSetChoices")
+}
+
+func testLinkChoices(body jquery.JQuery) {
+	logInfo("begin testLinkChoices")
+	states := map[string][]string{
+		"US":     {"California", "Texas"},
+		"Canada": {"Ontario", "Quebec"},
+	}
+	address := struct {
+		Country string `choice:"US,Canada,Mexico"`
+		State   string `choice:"California"`
+	}{"Canada", ""}
+	j, e := htmlctrl.Struct(&address, "link choices", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("link choices: unexpected error: %s", e))
+	}
+	body.Append(j)
+	country, state := j.Find("select").Eq(0), j.Find("select").Eq(1)
+	e = htmlctrl.LinkChoices(country, state, func(value string) []string {
+		return states[value]
+	})
+	if e != nil {
+		logError(fmt.Sprintf("link choices: unexpected error: %s", e))
+	}
+	if address.State != "Ontario" {
+		logError(fmt.Sprintf("link choices: state is '%s', expected 'Ontario' once linked", address.State))
+	}
+	state.SetVal("Quebec").Trigger(jquery.CHANGE)
+	country.SetVal("US").Trigger(jquery.CHANGE)
+	if address.State != "California" || state.Find("option").Length != 2 {
+		logError(fmt.Sprintf("link choices: state is '%s', expected 'California' after changing country",
+			address.State))
+	}
+	country.SetVal("Mexico").Trigger(jquery.CHANGE)
+	if address.State != "California" || state.Find("option").Length != 2 || htmlctrl.FormValid(j) {
+		logError(fmt.Sprintf("link choices: state is '%s', expected 'California' to be kept and marked invalid "+
+			"when there are no choices", address.State))
+	}
+	country.SetVal("Canada").Trigger(jquery.CHANGE)
+	if address.State != "Ontario" || !htmlctrl.FormValid(j) {
+		logError(fmt.Sprintf("link choices: state is '%s', expected a valid 'Ontario' once there are choices again",
+			address.State))
+	}
+	testLinkClearableChoices(body)
+	logInfo("end testLinkChoices")
+}
+
+func testLinkClearableChoices(body jquery.JQuery) {
+	logInfo("begin testLinkClearableChoices")
+	address := struct {
+		Country string `choice:"US,Canada" clear:"true"`
+		State   string `choice:"California" clear:"true"`
+	}{"Canada", ""}
+	j, e := htmlctrl.Struct(&address, "link clearable choices", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("link clearable choices: unexpected error: %s", e))
+	}
+	body.Append(j)
+	country, state := j.Find(".go-clearable").Eq(0), j.Find(".go-clearable").Eq(1)
+	e = htmlctrl.LinkChoices(country, state, func(value string) []string {
+		if value == "US" {
+			return []string{"California", "Texas"}
+		}
+		return []string{"Ontario", "Quebec"}
+	})
+	if e != nil {
+		logError(fmt.Sprintf("link clearable choices: unexpected error: %s", e))
+	}
+	if address.State != "Ontario" {
+		logError(fmt.Sprintf("link clearable choices: state is '%s', expected 'Ontario' once linked",
+			address.State))
+	}
+	country.Find("select").SetVal("US").Trigger(jquery.CHANGE)
+	if address.State != "California" || state.Find("option").Length != 2 {
+		logError(fmt.Sprintf("link clearable choices: state is '%s', expected 'California' after changing "+
+			"country", address.State))
+	}
+	if e := htmlctrl.SetChoices(state, []string{"Texas"}); e != nil || address.State != "Texas" {
+		logError(fmt.Sprintf("link clearable choices: state is '%s' with error %v, expected 'Texas'",
+			address.State, e))
+	}
+	logInfo("end testLinkClearableChoices")
+}
+
+type mode string
+
+const (
+	modeFast mode = "fast"
+	modeSafe mode = "safe"
+)
+
+func testStringEnum(body jquery.JQuery) {
+	logInfo("begin testStringEnum")
+	htmlctrl.RegisterStringEnum(reflect.TypeOf(modeFast), []string{string(modeFast), string(modeSafe)})
+	modes := struct {
+		M mode
+		R mode `choice:"fast,safe" widget:"radio"`
+	}{modeSafe, modeFast}
+	j, e := htmlctrl.Struct(&modes, "enum", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("enum: unexpected error: %s", e))
+	}
+	body.Append(j)
+	sel := j.Find("select")
+	if sel.Length != 1 || sel.Find("option").Length != 2 {
+		logError(fmt.Sprintf("enum: found %d selects, expected 1 with 2 options", sel.Length))
+		return
+	}
+	if val := sel.Val(); val != "safe" {
+		logError(fmt.Sprintf("enum: value is '%s', expected 'safe'", val))
+	}
+	sel.SetVal("fast").Trigger(jquery.CHANGE)
+	if modes.M != modeFast {
+		logError(fmt.Sprintf("enum: M is '%s', expected 'fast'", modes.M))
+	}
+	if n := j.Find("input[type=radio]").Length; n != 2 {
+		logError(fmt.Sprintf("enum: found %d radio buttons, expected the choice tag to give 2", n))
+	}
+	logInfo("end testStringEnum")
+}
+
+func testMultiSelect(body jquery.JQuery) {
+	logInfo("begin testMultiSelect")
+	tags := struct {
+		T []string `choice:"red,green,blue" widget:"multiselect"`
+	}{[]string{"blue"}}
+	j, e := htmlctrl.Struct(&tags, "multiselect", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("multiselect: unexpected error: %s", e))
+	}
+	body.Append(j)
+	sel := j.Find("select[multiple]")
+	if sel.Length != 1 {
+		logError(fmt.Sprintf("multiselect: found %d multiple selects, expected 1", sel.Length))
+		return
+	}
+	options := sel.Get(0).Get("options")
+	if options.Length() != 3 || !options.Index(2).Get("selected").Bool() || options.Index(0).Get("selected").Bool() {
+		logError("multiselect: expected only 'blue' to be selected")
+	}
+	options.Index(0).Set("selected", true)
+	sel.Trigger(jquery.CHANGE)
+	if !reflect.DeepEqual(tags.T, []string{"red", "blue"}) {
+		logError(fmt.Sprintf("multiselect: T is %v, expected [red blue]", tags.T))
+	}
+	options.Index(0).Set("selected", false)
+	options.Index(2).Set("selected", false)
+	sel.Trigger(jquery.CHANGE)
+	if len(tags.T) != 0 {
+		logError(fmt.Sprintf("multiselect: T is %v, expected it to be empty", tags.T))
+	}
+
+	// An option that fails valid, or a selection that fails the slice validator, reverts the whole change
+	colors := []string{"red"}
+	notGreen := htmlctrl.ValidateString(func(s string) bool { return s != "green" })
+	one := htmlctrl.ValidatorFunc(func(i interface{}) bool { return len(i.([]string)) <= 1 })
+	j, e = htmlctrl.MultiSelect(&colors, []string{"red", "green", "blue"}, "", "", "", notGreen,
+		htmlctrl.WithSliceValidator(one))
+	if e != nil {
+		logError(fmt.Sprintf("multiselect valid: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	options = j.Get(0).Get("options")
+	options.Index(0).Set("selected", false)
+	options.Index(1).Set("selected", true)
+	j.Trigger(jquery.CHANGE)
+	if !reflect.DeepEqual(colors, []string{"red"}) || options.Index(1).Get("selected").Bool() ||
+		!options.Index(0).Get("selected").Bool() {
+		logError(fmt.Sprintf("multiselect valid: colors is %v, expected 'green' to be reverted to [red]", colors))
+	}
+	options.Index(2).Set("selected", true)
+	j.Trigger(jquery.CHANGE)
+	if !reflect.DeepEqual(colors, []string{"red"}) || options.Index(2).Get("selected").Bool() {
+		logError(fmt.Sprintf("multiselect valid: colors is %v, expected [red blue] to be reverted to [red]", colors))
+	}
+	options.Index(0).Set("selected", false)
+	options.Index(2).Set("selected", true)
+	j.Trigger(jquery.CHANGE)
+	if !reflect.DeepEqual(colors, []string{"blue"}) {
+		logError(fmt.Sprintf("multiselect valid: colors is %v, expected [blue]", colors))
+	}
+
+	bad := []string{"purple"}
+	_, e = htmlctrl.MultiSelect(&bad, []string{"red"}, "", "", "", nil)
+	if e == nil {
+		logError("multiselect: expected error for element not among choices")
+	}
+	logInfo("end testMultiSelect")
+}
+
+func testAsyncValidator(body jquery.JQuery) {
+	logInfo("begin testAsyncValidator")
+	// The result is ready right away but only received once the control's goroutine runs
+	notTaken := htmlctrl.AsyncValidatorFunc(func(i interface{}) <-chan bool {
+		result := make(chan bool, 1)
+		result <- i.(string) != "taken"
+		return result
+	})
+	name := "free"
+	j, e := htmlctrl.String(&name, "async", "", "", nil, htmlctrl.WithAsyncValidator(notTaken))
+	if e != nil {
+		logError(fmt.Sprintf("async: unexpected error: %s", e))
+	}
+	body.Append(j)
+	j.SetVal("taken").Trigger(jquery.CHANGE)
+	if name != "taken" {
+		logError(fmt.Sprintf("async: name is '%s', expected 'taken' to be accepted while pending", name))
+	}
+	if !j.HasClass("go-pending") {
+		logError("async: expected pending class while the check is running")
+	}
+
+	latest := "free"
+	jl, e := htmlctrl.String(&latest, "async latest", "", "", nil, htmlctrl.WithAsyncValidator(notTaken))
+	if e != nil {
+		logError(fmt.Sprintf("async latest: unexpected error: %s", e))
+	}
+	body.Append(jl)
+	jl.SetVal("taken").Trigger(jquery.CHANGE)
+	jl.SetVal("other").Trigger(jquery.CHANGE)
+
+	js.Global.Call("setTimeout", func() {
+		if name != "free" {
+			logError(fmt.Sprintf("async: name is '%s', expected it to be reverted to 'free'", name))
+		}
+		if val := j.Val(); val != "free" {
+			logError(fmt.Sprintf("async: input is '%s', expected 'free'", val))
+		}
+		if j.HasClass("go-pending") || !j.HasClass("go-invalid") {
+			logError("async: expected invalid class and no pending class after the check failed")
+		}
+		if latest != "other" || jl.HasClass("go-invalid") || jl.HasClass("go-pending") {
+			logError(fmt.Sprintf("async latest: value is '%s', expected 'other' with the stale result ignored", latest))
+		}
+		logInfo("end testAsyncValidator")
+	}, 100)
+}
+
+func testDuplicate(body jquery.JQuery) {
+	logInfo("begin testDuplicate")
+	type item struct {
+		Name string
+		Tags []string
+	}
+	items := struct {
+		Items []item  `duplicate:"true"`
+		Ptrs  []*item `duplicate:"true"`
+	}{[]item{{"a", []string{"x"}}}, []*item{{"p", nil}}}
+	j, e := htmlctrl.Struct(&items, "duplicate", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("duplicate: unexpected error: %s", e))
+	}
+	body.Append(j)
+	dupBtns := j.Find("button:contains('Duplicate')")
+	if dupBtns.Length != 2 {
+		logError(fmt.Sprintf("duplicate: found %d duplicate buttons, expected 2", dupBtns.Length))
+		return
+	}
+	dupBtns.First().Trigger(jquery.CLICK)
+	if len(items.Items) != 2 || !reflect.DeepEqual(items.Items[0], items.Items[1]) {
+		logError(fmt.Sprintf("duplicate: Items is %v, expected two equal elements", items.Items))
+		return
+	}
+	items.Items[1].Tags[0] = "y"
+	if items.Items[0].Tags[0] != "x" {
+		logError("duplicate: changing the copy's Tags changed the original")
+	}
+	// The list is rebuilt after each change
+	j.Find("button:contains('Duplicate')").Last().Trigger(jquery.CLICK)
+	if len(items.Ptrs) != 2 || items.Ptrs[0] == items.Ptrs[1] || !reflect.DeepEqual(items.Ptrs[0], items.Ptrs[1]) {
+		logError(fmt.Sprintf("duplicate: Ptrs is %v, expected two distinct pointers to equal elements", items.Ptrs))
+		return
+	}
+	items.Ptrs[1].Name = "q"
+	if items.Ptrs[0].Name != "p" {
+		logError("duplicate: changing the copied pointer's Name changed the original")
+	}
+	// Elements that are slices themselves don't get the options of the outer slice
+	nested := struct {
+		N [][]int `duplicate:"true" ordered:"true"`
+	}{[][]int{{1, 2}}}
+	j, e = htmlctrl.Struct(&nested, "duplicate nested", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("duplicate nested: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	if n := j.Find("button:contains('Duplicate')").Length; n != 1 {
+		logError(fmt.Sprintf("duplicate nested: found %d duplicate buttons, expected only the outer slice's", n))
+	}
+	if n := j.Find("ol").Length; n != 1 {
+		logError(fmt.Sprintf("duplicate nested: found %d ol tags, expected only the outer slice to be ordered", n))
+	}
+	logInfo("end testDuplicate")
+}
+
+func testBoolString(body jquery.JQuery) {
+	logInfo("begin testBoolString")
+	legacy := struct {
+		Enabled string `widget:"boolstring"`
+		Active  string `widget:"boolstring" boolvals:"yes,no"`
+	}{"true", ""}
+	j, e := htmlctrl.Struct(&legacy, "boolstring", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("boolstring: unexpected error: %s", e))
+	}
+	body.Append(j)
+	boxes := j.Find("input[type=checkbox]")
+	if boxes.Length != 2 {
+		logError(fmt.Sprintf("boolstring: found %d checkboxes, expected 2", boxes.Length))
+		return
+	}
+	enabled, active := boxes.First(), boxes.Last()
+	if !enabled.Prop("checked").(bool) || active.Prop("checked").(bool) {
+		logError("boolstring: expected only Enabled to start out checked")
+	}
+	if legacy.Active != "no" {
+		logError(fmt.Sprintf("boolstring: Active is '%s', expected an empty string to become 'no'", legacy.Active))
+	}
+	enabled.SetProp("checked", false).Trigger(jquery.CHANGE)
+	if legacy.Enabled != "false" {
+		logError(fmt.Sprintf("boolstring: Enabled is '%s', expected 'false'", legacy.Enabled))
+	}
+	active.SetProp("checked", true).Trigger(jquery.CHANGE)
+	if legacy.Active != "yes" {
+		logError(fmt.Sprintf("boolstring: Active is '%s', expected 'yes'", legacy.Active))
+	}
+
+	unknown := "maybe"
+	_, e = htmlctrl.BoolString(&unknown, "yes", "no", "", "", "", nil)
+	if e == nil {
+		logError("boolstring: expected error for a value that is neither true nor false")
+	}
+	logInfo("end testBoolString")
+}
+
+func testClear(body jquery.JQuery) {
+	logInfo("begin testClear")
+	n := 7
+	fields := struct {
+		S string  `clear:"true"`
+		I int     `clear:"true"`
+		F float64 `clear:"true"`
+		C string  `clear:"true" choice:"a,b,c"`
+		P *int    `clear:"true"`
+	}{"text", 3, 1.5, "c", &n}
+	j, e := htmlctrl.Struct(&fields, "clear", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("clear: unexpected error: %s", e))
+	}
+	body.Append(j)
+	buttons := j.Find(".go-clear")
+	if buttons.Length != 5 {
+		logError(fmt.Sprintf("clear: found %d clear buttons, expected 5", buttons.Length))
+		return
+	}
+	buttons.Trigger(jquery.CLICK)
+	if fields.S != "" || fields.I != 0 || fields.F != 0 || fields.C != "a" || fields.P != nil {
+		logError(fmt.Sprintf("clear: fields are %+v, expected zero values, the first choice, and a nil pointer", fields))
+	}
+	if val := j.Find(".go-string").Val(); val != "" {
+		logError(fmt.Sprintf("clear: string input is '%s', expected it to be empty", val))
+	}
+
+	nonEmpty := "text"
+	jn, e := htmlctrl.String(&nonEmpty, "clear invalid", "", "", htmlctrl.NonEmptyString, htmlctrl.WithClear())
+	if e != nil {
+		logError(fmt.Sprintf("clear invalid: unexpected error: %s", e))
+	}
+	body.Append(jn)
+	jn.Find(".go-clear").Trigger(jquery.CLICK)
+	if nonEmpty != "text" {
+		logError(fmt.Sprintf("clear invalid: value is '%s', expected clearing to be rejected by the validator", nonEmpty))
+	}
+	logInfo("end testClear")
+}
+
+func testSliceTable(body jquery.JQuery) {
+	logInfo("begin testSliceTable")
+	type row struct {
+		Name  string
+		Count int
+		hide  bool
+	}
+	rows := struct {
+		Rows []row `table:"true"`
+	}{[]row{{"a", 1, false}, {"b", 2, false}}}
+	j, e := htmlctrl.Struct(&rows, "table", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("table: unexpected error: %s", e))
+	}
+	body.Append(j)
+	table := j.Find("table.go-slice-table")
+	if table.Length != 1 {
+		logError(fmt.Sprintf("table: found %d tables, expected 1", table.Length))
+		return
+	}
+	var headers []string
+	table.Find("thead th").Each(func(i int, th interface{}) {
+		headers = append(headers, jq(th).Text())
+	})
+	if !reflect.DeepEqual(headers, []string{"Name", "Count", ""}) {
+		logError(fmt.Sprintf("table: headers are %q, expected [Name Count ]", headers))
+	}
+	// One row per element plus the add button's row
+	bodyRows := table.Children("tr")
+	if bodyRows.Length != 3 {
+		logError(fmt.Sprintf("table: found %d rows, expected 3", bodyRows.Length))
+		return
+	}
+	if n := bodyRows.First().Children("td").Length; n != 3 {
+		logError(fmt.Sprintf("table: first row has %d cells, expected 3", n))
+	}
+	if n := table.Find("label").Length; n != 0 {
+		logError(fmt.Sprintf("table: found %d labels, expected the header to replace them", n))
+	}
+	bodyRows.Eq(1).Find("input[type=number]").SetVal(5).Trigger(jquery.CHANGE)
+	if rows.Rows[1].Count != 5 {
+		logError(fmt.Sprintf("table: Count of row 1 is %d, expected 5", rows.Rows[1].Count))
+	}
+	bodyRows.Last().Find("button").Trigger(jquery.CLICK)
+	if len(rows.Rows) != 3 || table.Children("tr").Length != 4 {
+		logError(fmt.Sprintf("table: slice has %d elements after adding, expected 3 with a row each", len(rows.Rows)))
+	}
+
+	ints := []int{1}
+	_, e = htmlctrl.Slice(&ints, "", "", "", 0, 0, 0, nil, htmlctrl.WithTable())
+	if e == nil {
+		logError("table: expected error for elements that aren't structs")
+	}
+	logInfo("end testSliceTable")
+}
+
+func testSliceTableSort(body jquery.JQuery) {
+	logInfo("begin testSliceTableSort")
+	type person struct {
+		Name string
+		Age  int
+	}
+	form := struct {
+		People []person `widget:"table"`
+	}{[]person{{"b", 30}, {"a", 40}, {"c", 20}}}
+	j, e := htmlctrl.Struct(&form, "table sort", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("table sort: unexpected error: %s", e))
+	}
+	body.Append(j)
+	names := func() string {
+		s := ""
+		for _, p := range form.People {
+			s += p.Name
+		}
+		return s
+	}
+	shown := func() string {
+		s := ""
+		j.Find("table tr .go-string").Each(func(i int, elem interface{}) {
+			s += jq(elem).Val()
+		})
+		return s
+	}
+	cases := []struct {
+		column, expected string
+	}{
+		{"Age", "cba"},
+		{"Age", "abc"},
+		{"Name", "abc"},
+		{"Name", "cba"},
+	}
+	for _, c := range cases {
+		j.Find("th").Filter(fmt.Sprintf(":contains('%s')", c.column)).Trigger(jquery.CLICK)
+		if names() != c.expected || shown() != c.expected {
+			logError(fmt.Sprintf("table sort: by %s got %s showing %s, expected %s", c.column, names(), shown(),
+				c.expected))
+		}
+	}
+	if n := j.Find(".go-slice-sort-desc").Length; n != 1 {
+		logError(fmt.Sprintf("table sort: found %d headers marked descending, expected 1", n))
+	}
+	logInfo("end testSliceTableSort")
+}
+
+func testOptionalNumbers(body jquery.JQuery) {
+	logInfo("begin testOptionalNumbers")
+	limit := 3
+	form := struct {
+		Limit *int
+		Ratio *float64 `min:"0" max:"1"`
+	}{Limit: &limit}
+	j, e := htmlctrl.Struct(&form, "optional", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("optional: unexpected error: %s", e))
+	}
+	body.Append(j)
+	var olds, news []interface{}
+	htmlctrl.OnChange(j, func(old, new interface{}) {
+		olds, news = append(olds, old), append(news, new)
+	})
+	limitInput, ratioInput := j.Find(".go-int"), j.Find(".go-float64")
+	if val := ratioInput.Val(); val != "" {
+		logError(fmt.Sprintf("optional: nil Ratio is shown as '%s', expected an empty input", val))
+	}
+	limitInput.SetVal("").Trigger(jquery.CHANGE)
+	if form.Limit != nil {
+		logError(fmt.Sprintf("optional: Limit is %d, expected nil after emptying the input", *form.Limit))
+	}
+	limitInput.SetVal("3").Trigger(jquery.CHANGE)
+	if form.Limit == nil || *form.Limit != 3 {
+		logError("optional: expected Limit to point to 3 after entering the same number again")
+	}
+	ratioInput.SetVal("2").Trigger(jquery.CHANGE)
+	if form.Ratio != nil || ratioInput.Val() != "" {
+		logError("optional: expected an out of range Ratio to be reverted to empty")
+	}
+	ratioInput.SetVal("0.5").Trigger(jquery.CHANGE)
+	if form.Ratio == nil || *form.Ratio != 0.5 {
+		logError("optional: expected Ratio to point to 0.5")
+	}
+	expectedOlds, expectedNews := []interface{}{3, nil, nil}, []interface{}{nil, 3, 0.5}
+	if !reflect.DeepEqual(olds, expectedOlds) || !reflect.DeepEqual(news, expectedNews) {
+		logError(fmt.Sprintf("optional: changes were %v to %v, expected [3 <nil> <nil>] to [<nil> 3 0.5]", olds, news))
+	}
+	logInfo("end testOptionalNumbers")
+}
+
+func testCode(body jquery.JQuery) {
+	logInfo("begin testCode")
+	config := struct {
+		Name   string `widget:"code"`
+		Script string `widget:"code" multiline:"true"`
+	}{"my_var", "x := 1\ny := 2"}
+	j, e := htmlctrl.Struct(&config, "code", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("code: unexpected error: %s", e))
+	}
+	body.Append(j)
+	name, script := j.Find("input.go-code"), j.Find("textarea.go-code")
+	if name.Length != 1 || script.Length != 1 {
+		logError(fmt.Sprintf("code: found %d inputs and %d textareas, expected 1 of each", name.Length, script.Length))
+		return
+	}
+	if name.Attr("spellcheck") != "false" || name.Attr("autocapitalize") != "off" {
+		logError("code: expected spellcheck and autocapitalize to be off")
+	}
+	if val := script.Val(); val != config.Script {
+		logError(fmt.Sprintf("code: textarea is %q, expected %q", val, config.Script))
+	}
+	script.SetVal("z := 3").Trigger(jquery.CHANGE)
+	if config.Script != "z := 3" {
+		logError(fmt.Sprintf("code: Script is %q, expected 'z := 3'", config.Script))
+	}
+	logInfo("end testCode")
+}
+
+func testChoiceFilter(body jquery.JQuery) {
+	logInfo("begin testChoiceFilter")
+	opts := []string{
+		"apple",
+		"apricot",
+		"banana",
+		"blueberry",
+		"cherry",
+	}
+	cases := []struct {
+		name  string
+		s     string
+		valid htmlctrl.Validator
+	}{
+		{"cf1", "banana", nil},
+		{"cf2", "", htmlctrl.ValidateString(func(c string) bool {
+			if c == "cherry" {
+				log("cf2 can't be 'cherry'")
+			}
+			return c != "cherry"
+		})},
+	}
+	choices := jq("<div>").AddClass("choices")
+	for _, c := range cases {
+		logInfo(fmt.Sprintf("test case: %#v", c))
+		j, e := htmlctrl.ChoiceFilter(&c.s, opts, c.name, "choice-filter-id", "choice-filter-class", c.valid)
+		if e != nil {
+			logError(fmt.Sprintf("%s: unexpected error: %s", c.name, e))
+		}
+		if title := j.Attr("title"); title != c.name {
+			logError(fmt.Sprintf("%s: title is %s, expected %s", c.name, title, c.name))
+		}
+		choices.Append(j)
+		c := &c
+		choices.Append(jq("<button>").SetText("verify "+c.name).Call(jquery.CLICK, func() {
+			log(c.name, c.s)
+		}))
+	}
+	body.Append(choices)
+
+	s := "apple"
+	j, _ := htmlctrl.ChoiceFilter(&s, opts, "filter", "", "", nil)
+	body.Append(j)
+	input := j.Find("input")
+	input.SetVal("ap").Trigger("input")
+	if shown := j.Find("li:visible").Length; shown != 2 {
+		logError(fmt.Sprintf("filter: %d choices shown for 'ap', expected 2", shown))
+	}
+	j.Find("li:visible").Last().Trigger(jquery.MOUSEDOWN)
+	if s != "apricot" {
+		logError(fmt.Sprintf("filter: value is '%s' after selecting, expected 'apricot'", s))
+	}
+	j.Remove()
+	logInfo("end testChoiceFilter")
+}
+
 type sliceCase interface {
 	name() string
 	slice() interface{}
@@ -484,18 +1334,73 @@ func testSlices(body jquery.JQuery) {
 	}
 	testSlice(body, cases)
 
-	logInfo("begin testSlice *bool")
-	b1, b2 := true, false
-	cases = []sliceCase{
-		&sliceBoolPtrCase{"[]*bool1", []*bool{&b1, &b2}, htmlctrl.ValidateBool(func(b bool) bool {
-			log("bool is locked at true")
-			return b
-		})},
-		&sliceBoolPtrCase{"[]*bool2", []*bool{}, nil},
+	logInfo("begin testSlice labels")
+	labeled := []bool{true}
+	j, e := htmlctrl.SliceLabels(&labeled, "labels", "slice-id", "slice-class", 0, 0, 0, nil,
+		htmlctrl.Labels{SliceAdd: "add", SliceDel: "remove"})
+	if e != nil {
+		logError(fmt.Sprintf("labels: unexpected error: %s", e))
 	}
-	testSlice(body, cases)
-
-	logInfo("begin testSlice int")
+	if text := j.Find("li:not(.go-slice-add) button").Text(); text != "remove" {
+		logError(fmt.Sprintf("labels: delete button text is '%s', expected 'remove'", text))
+	}
+	if text := j.Children(".go-slice-add").Children("button").Text(); text != "add" {
+		logError(fmt.Sprintf("labels: add button text is '%s', expected 'add'", text))
+	}
+	body.Append(j)
+	if !j.Is("ul") {
+		logError("labels: expected slice to be a ul tag")
+	}
+
+	texts := struct {
+		Custom  [][]int `addtext:"New row" deltext:"Drop"`
+		Default []int
+	}{[][]int{{1}}, []int{1}}
+	j, e = htmlctrl.Struct(&texts, "slice text", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("slice text: unexpected error: %s", e))
+	}
+	body.Append(j)
+	custom := j.Find(".go-slice").First()
+	if text := custom.Children(".go-slice-add").Children("button").Text(); text != "New row" {
+		logError(fmt.Sprintf("slice text: add button text is '%s', expected 'New row'", text))
+	}
+	if text := custom.Children("li").First().Children("button").Text(); text != "Drop" {
+		logError(fmt.Sprintf("slice text: delete button text is '%s', expected 'Drop'", text))
+	}
+	inner := custom.Find(".go-slice .go-slice-add button")
+	if text := inner.Text(); text != htmlctrl.SliceAddText {
+		logError(fmt.Sprintf("slice text: inner add button text is '%s', expected the default", text))
+	}
+	other := j.Find(".go-slice").Last()
+	if text := other.Children(".go-slice-add").Children("button").Text(); text != htmlctrl.SliceAddText {
+		logError(fmt.Sprintf("slice text: other add button text is '%s', expected the default", text))
+	}
+
+	steps := struct {
+		Steps []string `ordered:"true"`
+	}{[]string{"first", "second"}}
+	j, e = htmlctrl.Struct(&steps, "ordered", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("ordered: unexpected error: %s", e))
+	}
+	body.Append(j)
+	if ol := j.Find("ol.go-slice"); ol.Length != 1 || ol.Children("li").Length != 3 {
+		logError("ordered: expected an ol tag with a list item for each element and the add button")
+	}
+
+	logInfo("begin testSlice *bool")
+	b1, b2 := true, false
+	cases = []sliceCase{
+		&sliceBoolPtrCase{"[]*bool1", []*bool{&b1, &b2}, htmlctrl.ValidateBool(func(b bool) bool {
+			log("bool is locked at true")
+			return b
+		})},
+		&sliceBoolPtrCase{"[]*bool2", []*bool{}, nil},
+	}
+	testSlice(body, cases)
+
+	logInfo("begin testSlice int")
 	cases = []sliceCase{
 		&sliceIntCase{"[]int1", []int{2, 4}, 0, 50, 2, htmlctrl.ValidateInt(func(i int) bool {
 			allowed := i != 3 && i != 5 && i != 7
@@ -607,6 +1512,105 @@ func testSlices(body jquery.JQuery) {
 	}
 	testSlice(body, cases)
 
+	logInfo("begin testSlice delete middle")
+	middle := []string{"a", "b", "c"}
+	j, e = htmlctrl.Slice(&middle, "delete middle", "", "", 0, 0, 0, nil)
+	if e != nil {
+		logError(fmt.Sprintf("delete middle: unexpected error: %s", e))
+	}
+	// Something other than an element before the list items shouldn't throw off the index
+	j.Prepend(jq("<span>").SetText("items"))
+	body.Append(j)
+	j.Find("li").Eq(1).Children("button").Trigger(jquery.CLICK)
+	if !reflect.DeepEqual(middle, []string{"a", "c"}) {
+		logError(fmt.Sprintf("delete middle: slice is %v, expected [a c]", middle))
+	}
+	j.Find("li").Eq(1).Children("button").Trigger(jquery.CLICK)
+	if !reflect.DeepEqual(middle, []string{"a"}) {
+		logError(fmt.Sprintf("delete middle: slice is %v, expected [a] after deleting again", middle))
+	}
+
+	logInfo("begin testSlice nil *struct")
+	type foo struct {
+		A int
+	}
+	foos := []*foo{nil, {3}}
+	j, e = htmlctrl.Slice(&foos, "nil elements", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("nil elements: unexpected error: %s", e))
+	}
+	body.Append(j)
+	toggle := j.Find(".go-nil input")
+	if toggle.Length != 1 {
+		logError(fmt.Sprintf("nil elements: found %d toggles, expected 1", toggle.Length))
+	}
+	toggle.SetProp("checked", true).Trigger(jquery.CHANGE)
+	if foos[0] == nil || foos[1].A != 3 {
+		logError(fmt.Sprintf("nil elements: slice is %v, expected the first element to be allocated", foos))
+	}
+	if n := j.Find(".go-nil").Length; n != 0 {
+		logError(fmt.Sprintf("nil elements: found %d toggles after enabling, expected 0", n))
+	}
+
+	logInfo("begin testSlice slicevalid")
+	unique := htmlctrl.ValidatorFunc(func(i interface{}) bool {
+		seen := map[string]bool{}
+		for _, s := range i.([]string) {
+			if seen[s] {
+				log("elements must be unique")
+				return false
+			}
+			seen[s] = true
+		}
+		return true
+	})
+	names := []string{"a", ""}
+	j, e = htmlctrl.Slice(&names, "unique", "", "", 0, 0, 0, nil, htmlctrl.WithSliceValidator(unique))
+	if e != nil {
+		logError(fmt.Sprintf("unique: unexpected error: %s", e))
+	}
+	body.Append(j)
+	j.Children(".go-slice-add").Children("button").Trigger(jquery.CLICK)
+	if !reflect.DeepEqual(names, []string{"a", ""}) {
+		logError(fmt.Sprintf("unique: slice is %q, expected adding a second '' to be rejected", names))
+	}
+	j.Find("input").Eq(1).SetVal("a").Trigger(jquery.CHANGE)
+	if !reflect.DeepEqual(names, []string{"a", ""}) {
+		logError(fmt.Sprintf("unique: slice is %q, expected changing to a duplicate to be rejected", names))
+	}
+	if val := j.Find("input").Eq(1).Val(); val != "" {
+		logError(fmt.Sprintf("unique: input is '%s' after rejected change, expected ''", val))
+	}
+	j.Find("input").Eq(1).SetVal("b").Trigger(jquery.CHANGE)
+	j.Children(".go-slice-add").Children("button").Trigger(jquery.CLICK)
+	if !reflect.DeepEqual(names, []string{"a", "b", ""}) {
+		logError(fmt.Sprintf("unique: slice is %q, expected [a b ]", names))
+	}
+
+	logInfo("begin testSlice [][]float64")
+	nested := [][]float64{{1, 2}, {3}}
+	j, e = htmlctrl.Slice(&nested, "[][]float64", "", "", 0, 10, 0.5, nil)
+	if e != nil {
+		logError(fmt.Sprintf("[][]float64: unexpected error: %s", e))
+	}
+	body.Append(j)
+	inputs := j.Find("input")
+	if inputs.Length != 3 {
+		logError(fmt.Sprintf("[][]float64: found %d inputs, expected 3", inputs.Length))
+	}
+	inputs.Each(func(i int, elem interface{}) {
+		input := jq(elem)
+		if min, max, step := input.Attr("min"), input.Attr("max"), input.Attr("step"); min != "0" || max != "10" ||
+			step != "0.5" {
+			logError(fmt.Sprintf("[][]float64: input %d has min %s, max %s, step %s, expected 0, 10, 0.5", i, min,
+				max, step))
+		}
+	})
+	inputs.Eq(2).SetVal(11).Trigger(jquery.CHANGE)
+	if nested[1][0] != 3 {
+		logError(fmt.Sprintf("[][]float64: element is %v, expected 11 to be rejected by max", nested[1][0]))
+	}
+
 	logInfo("end testSlices")
 }
 
@@ -631,6 +1635,185 @@ func testSlice(body jquery.JQuery, cases []sliceCase) {
 	body.Append(slices)
 }
 
+func testSliceCSV(body jquery.JQuery) {
+	logInfo("begin testSliceCSV")
+	csvs := jq("<div>")
+
+	strs := []string{"x"}
+	j, e := htmlctrl.SliceCSV(&strs, "csv strings", "csv-id", "csv-class", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("csv strings: unexpected error: %s", e))
+	}
+	if val := j.Val(); val != "x" {
+		logError(fmt.Sprintf("csv strings: value is '%s', expected 'x'", val))
+	}
+	j.SetVal("a, b ,c").Trigger(jquery.CHANGE)
+	if fmt.Sprint(strs) != "[a b c]" {
+		logError(fmt.Sprintf("csv strings: slice is %v, expected [a b c]", strs))
+	}
+	if val := j.Val(); val != "a,b,c" {
+		logError(fmt.Sprintf("csv strings: value is '%s', expected 'a,b,c'", val))
+	}
+	csvs.Append(j)
+
+	ints := []int{}
+	j, e = htmlctrl.SliceCSV(&ints, "csv ints", "csv-id", "csv-class", 0, 10, 1, nil)
+	if e != nil {
+		logError(fmt.Sprintf("csv ints: unexpected error: %s", e))
+	}
+	j.SetVal("1,2,3").Trigger(jquery.CHANGE)
+	if fmt.Sprint(ints) != "[1 2 3]" {
+		logError(fmt.Sprintf("csv ints: slice is %v, expected [1 2 3]", ints))
+	}
+	j.SetVal("4,x").Trigger(jquery.CHANGE)
+	if fmt.Sprint(ints) != "[1 2 3]" || j.Val() != "1,2,3" {
+		logError(fmt.Sprintf("csv ints: slice is %v after invalid input, expected [1 2 3]", ints))
+	}
+	j.SetVal("4,11").Trigger(jquery.CHANGE)
+	if fmt.Sprint(ints) != "[1 2 3]" {
+		logError(fmt.Sprintf("csv ints: slice is %v after out of range input, expected [1 2 3]", ints))
+	}
+	j.SetVal("").Trigger(jquery.CHANGE)
+	if len(ints) != 0 {
+		logError(fmt.Sprintf("csv ints: slice is %v after clearing, expected []", ints))
+	}
+	csvs.Append(j)
+
+	body.Append(csvs)
+	logInfo("end testSliceCSV")
+}
+
+func testImage(body jquery.JQuery) {
+	logInfo("begin testImage")
+	// Just the signature is enough to be recognized as a PNG
+	png := []byte("\x89PNG\r\n\x1a\nrest")
+	avatar := struct {
+		Avatar []byte `image:"true"`
+	}{}
+	j, e := htmlctrl.Struct(&avatar, "image", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("image: unexpected error: %s", e))
+	}
+	body.Append(j)
+	img, input := j.Find("img.go-image-preview"), j.Find("input[type=file]")
+	if img.Length != 1 || input.Length != 1 {
+		logError(fmt.Sprintf("image: found %d imgs and %d file inputs, expected 1 of each", img.Length, input.Length))
+		return
+	}
+	// choose simulates the user choosing a file holding b
+	choose := func(b []byte) {
+		file := js.Global.Get("File").New([]interface{}{b}, "avatar.png", js.M{"type": "image/png"})
+		transfer := js.Global.Get("DataTransfer").New()
+		transfer.Get("items").Call("add", file)
+		input.Get(0).Set("files", transfer.Get("files"))
+		input.Trigger(jquery.CHANGE)
+	}
+	choose(png)
+	js.Global.Call("setTimeout", func() {
+		if string(avatar.Avatar) != string(png) {
+			logError(fmt.Sprintf("image: Avatar is %q, expected the bytes of the file", avatar.Avatar))
+		}
+		if src := img.Attr("src"); !strings.HasPrefix(src, "data:image/png;base64,") {
+			logError(fmt.Sprintf("image: src is '%s', expected a PNG data URL", src))
+		}
+		choose([]byte("not an image"))
+		js.Global.Call("setTimeout", func() {
+			if string(avatar.Avatar) != string(png) {
+				logError("image: expected a file that isn't an image to be rejected")
+			}
+			if j.Find(".go-image-error").Text() == "" {
+				logError("image: expected an error to be shown for a file that isn't an image")
+			}
+			logInfo("end testImage")
+		}, 100)
+	}, 100)
+}
+
+func testBytes(body jquery.JQuery) {
+	logInfo("begin testBytes")
+	hexed := struct {
+		Key []byte `encoding:"hex"`
+	}{[]byte{0xde, 0xad}}
+	j, e := htmlctrl.Struct(&hexed, "hex", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("hex: unexpected error: %s", e))
+	}
+	body.Append(j)
+	input := j.Find("input")
+	if val := input.Val(); val != "dead" {
+		logError(fmt.Sprintf("hex: value is '%s', expected 'dead'", val))
+	}
+	input.SetVal("DEADbeef").Trigger(jquery.CHANGE)
+	if !reflect.DeepEqual(hexed.Key, []byte{0xde, 0xad, 0xbe, 0xef}) {
+		logError(fmt.Sprintf("hex: key is %x, expected deadbeef", hexed.Key))
+	}
+	if val := input.Val(); val != "deadbeef" {
+		logError(fmt.Sprintf("hex: value is '%s', expected it to be normalized to 'deadbeef'", val))
+	}
+	for _, bad := range []string{"abc", "zz"} {
+		input.SetVal(bad).Trigger(jquery.CHANGE)
+		if !reflect.DeepEqual(hexed.Key, []byte{0xde, 0xad, 0xbe, 0xef}) {
+			logError(fmt.Sprintf("hex: key is %x after '%s', expected invalid hex to be reverted", hexed.Key, bad))
+		}
+		if val := input.Val(); val != "deadbeef" {
+			logError(fmt.Sprintf("hex: value is '%s' after '%s', expected 'deadbeef'", val, bad))
+		}
+	}
+
+	var b []byte
+	if _, e := htmlctrl.Bytes(&b, "rot13", "", "", "", nil); e == nil {
+		logError("bytes: expected error for unknown encoding")
+	}
+	logInfo("end testBytes")
+}
+
+func testRawJSON(body jquery.JQuery) {
+	logInfo("begin testRawJSON")
+	doc := struct {
+		Raw json.RawMessage
+	}{json.RawMessage(`{"a":1}`)}
+	j, e := htmlctrl.Struct(&doc, "raw json", "json-id", "json-class")
+	if e != nil {
+		logError(fmt.Sprintf("raw json: unexpected error: %s", e))
+	}
+	body.Append(j)
+	textarea := j.Find("textarea")
+	if textarea.Length != 1 {
+		logError("raw json: expected a textarea")
+	}
+	textarea.SetVal("{\n  \"b\": [1, 2]\n}").Trigger(jquery.CHANGE)
+	if string(doc.Raw) != `{"b":[1,2]}` {
+		logError(fmt.Sprintf("raw json: value is %s, expected {\"b\":[1,2]}", doc.Raw))
+	}
+	textarea.SetVal(`{"b": [1, 2}`).Trigger(jquery.CHANGE)
+	if string(doc.Raw) != `{"b":[1,2]}` {
+		logError(fmt.Sprintf("raw json: value is %s after invalid input, expected {\"b\":[1,2]}", doc.Raw))
+	}
+	if val := textarea.Val(); val != "{\n  \"b\": [\n    1,\n    2\n  ]\n}" {
+		logError(fmt.Sprintf("raw json: textarea shows %q after invalid input, expected the indented value", val))
+	}
+
+	soft := struct {
+		Raw json.RawMessage
+	}{json.RawMessage(`{"a":1}`)}
+	j, e = htmlctrl.Struct(&soft, "raw json soft", "", "", htmlctrl.WithSoftValidation())
+	if e != nil {
+		logError(fmt.Sprintf("raw json: unexpected error: %s", e))
+	}
+	body.Append(j)
+	textarea = j.Find("textarea")
+	textarea.SetVal(`{"a":`).Trigger(jquery.CHANGE)
+	if textarea.Val() != `{"a":` || !textarea.HasClass("go-invalid") || string(soft.Raw) != `{"a":1}` {
+		logError(fmt.Sprintf("raw json: soft stored %s showing %q, expected the invalid text kept and marked",
+			soft.Raw, textarea.Val()))
+	}
+	textarea.SetVal(`{"a": 2}`).Trigger(jquery.CHANGE)
+	if textarea.HasClass("go-invalid") || string(soft.Raw) != `{"a":2}` {
+		logError(fmt.Sprintf("raw json: soft stored %s, expected {\"a\":2} and no longer invalid", soft.Raw))
+	}
+	logInfo("end testRawJSON")
+}
+
 func testStruct(body jquery.JQuery) {
 	logInfo("begin testStruct")
 	Bptr := true
@@ -651,23 +1834,35 @@ func testStruct(body jquery.JQuery) {
 		I    int      `desc:"an int" id:"s1-I" class:"struct-int"`
 		Iptr *int     `desc:"int ptr"`
 		Ilim int      `desc:"limited int" min:"1" max:"10" step:"2" valid:"IntNot5"`
+		Ient int      `desc:"int that commits on enter" enter:"next"`
 		F    float64  `desc:"an float64" id:"s1-F" class:"struct-float64"`
 		Fptr *float64 `desc:"float64 ptr"`
 		Flim float64  `desc:"limited float64" min:"1.2" max:"10.5" step:"1.2" valid:"Float64Not5"`
+		Fthr float64  `desc:"throttled float64" min:"0" max:"100" throttle:"250ms"`
+		Fpct float64  `desc:"percent float64" widget:"percent" step:"0.05"`
 		S    string   `desc:"a string" id:"s1-S" class:"struct-string"`
 		Sptr *string  `desc:"string ptr"`
 		Slim string   `desc:"limited string" valid:"StringNotHello"`
+		Ssec string   `desc:"secret string" widget:"secret"`
 		C    string   `desc:"a choice" choice:"def,abc,invalid,hi" id:"s1-C" class:"struct-choice"`
 		Cptr *string  `desc:"choice ptr" choice:"def,abc,invalid,hi"`
 		Clim string   `desc:"limited choice" choice:"def,abc,invalid,hi" valid:"ChoiceNotInvalid"`
+		Cf   string   `desc:"filtered choice" choice:"def,abc,invalid,hi" widget:"filter"`
+		Cr   string   `desc:"radio choice" choice:"def,abc,invalid,hi" widget:"radio" valid:"ChoiceNotInvalid"`
 		St   St1      `desc:"inner struct" id:"s1-St" class:"struct-struct"`
+		Stf  St2      `desc:"inner struct in a fieldset" fieldset:"true"`
+		Csv  []string `desc:"comma separated strings" csv:"true"`
+		Ln   []string `desc:"one string per line" widget:"lines" valid:"StringNotHello"`
 	}{
 		false, false, &Bptr, true,
-		2, &Iptr, 1,
-		2.5, &Fptr, 1.2,
-		"a", &Sptr, "def",
-		"", &Sptr, "hi",
+		2, &Iptr, 1, 3,
+		2.5, &Fptr, 1.2, 50, 0.25,
+		"a", &Sptr, "def", "hunter2",
+		"", &Sptr, "hi", "abc", "hi",
 		St1{A: []St2{}},
+		St2{B: []int{1}},
+		[]string{"a", "b"},
+		[]string{"first", "second"},
 	}
 	htmlctrl.RegisterValidator("BoolTrue", htmlctrl.ValidateBool(func(b bool) bool {
 		log("bool is locked at true")
@@ -709,7 +1904,92 @@ func testStruct(body jquery.JQuery) {
 		logError("expected error when passing ptr to non-slice")
 	}
 
-	j, e := htmlctrl.Struct(&struct1, "struct1", "struct-id", "struct-class")
+	minOverMax := struct {
+		I int `min:"10" max:"1"`
+	}{}
+	_, e = htmlctrl.Struct(&minOverMax, "error", "struct-id", "struct-class")
+	if e == nil {
+		logError("expected error when min is greater than max")
+	}
+	zeroStep := struct {
+		F float64 `step:"0"`
+	}{}
+	_, e = htmlctrl.Struct(&zeroStep, "error", "struct-id", "struct-class")
+	if e == nil {
+		logError("expected error when step is 0")
+	}
+	negativeStep := struct {
+		F float64 `step:"-1"`
+	}{}
+	_, e = htmlctrl.Struct(&negativeStep, "error", "struct-id", "struct-class")
+	if e == nil {
+		logError("expected error when step is negative")
+	}
+
+	headed := struct {
+		A int
+		B int `heading:"Section B"`
+	}{}
+	j, e := htmlctrl.Struct(&headed, "headed", "struct-id", "struct-class")
+	if e != nil {
+		logError(fmt.Sprintf("%s: unexpected error: %s", "headed", e))
+	}
+	children := j.Children("*")
+	if !children.Eq(1).Is("h4") || children.Eq(1).Text() != "Section B" {
+		logError("headed: expected heading 'Section B' to be the second element")
+	}
+	if label := children.Eq(2).Find("label").Text(); label != "B" {
+		logError(fmt.Sprintf("headed: field after heading is %s, expected B", label))
+	}
+
+	type point struct {
+		X, Y float64
+	}
+	inline := struct {
+		P point `inline:"true"`
+	}{point{1, 2}}
+	j, e = htmlctrl.Struct(&inline, "inline", "struct-id", "struct-class")
+	if e != nil {
+		logError(fmt.Sprintf("%s: unexpected error: %s", "inline", e))
+	}
+	if n := j.Find(".go-struct-inline").Length; n != 1 {
+		logError(fmt.Sprintf("inline: found %d inline structs, expected 1", n))
+	}
+	if n := j.Find(".go-struct-inline > .go-struct-inline-field").Length; n != 2 {
+		logError(fmt.Sprintf("inline: found %d inline fields, expected 2", n))
+	}
+	body.Append(j)
+
+	htmlctrl.RegisterValidator("IntNot0", htmlctrl.ValidateInt(func(i int) bool {
+		return i != 0
+	}))
+	addValid := struct {
+		L []int `valid:"IntNot0" addvalid:"true"`
+	}{[]int{1}}
+	j, e = htmlctrl.Struct(&addValid, "addvalid", "struct-id", "struct-class")
+	if e != nil {
+		logError(fmt.Sprintf("%s: unexpected error: %s", "addvalid", e))
+	}
+	addBtn := func() jquery.JQuery {
+		return j.Find(".go-slice-add").Children("button")
+	}
+	if addBtn().Prop("disabled").(bool) {
+		logError("addvalid: add button disabled when all elements are valid")
+	}
+	addBtn().Trigger(jquery.CLICK)
+	if !addBtn().Prop("disabled").(bool) {
+		logError("addvalid: add button enabled with an invalid element")
+	}
+	addBtn().Trigger(jquery.CLICK)
+	if len(addValid.L) != 2 {
+		logError(fmt.Sprintf("addvalid: slice is %v, expected adding to be blocked", addValid.L))
+	}
+	j.Find("li input").Eq(1).SetVal(2).Trigger(jquery.CHANGE)
+	if addBtn().Prop("disabled").(bool) {
+		logError("addvalid: add button still disabled after fixing the invalid element")
+	}
+
+	j, e = htmlctrl.Struct(&struct1, "struct1", "struct-id", "struct-class")
 	if e != nil {
 		logError(fmt.Sprintf("%s: unexpected error: %s", "struct1", e))
 	}
@@ -723,3 +2003,2348 @@ func testStruct(body jquery.JQuery) {
 
 	logInfo("end testStruct")
 }
+
+func testInterface(body jquery.JQuery) {
+	logInfo("begin testInterface")
+	i := 1
+	ifaces := struct {
+		V interface{}
+		P interface{}
+	}{5, &i}
+	j, e := htmlctrl.Struct(&ifaces, "interfaces", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("interfaces: unexpected error: %s", e))
+	}
+	body.Append(j)
+	inputs := j.Find("input")
+	inputs.Eq(0).SetVal(7).Trigger(jquery.CHANGE)
+	if v, ok := ifaces.V.(int); !ok || v != 7 {
+		logError(fmt.Sprintf("interfaces: V is %#v, expected 7", ifaces.V))
+	}
+	inputs.Eq(1).SetVal(3).Trigger(jquery.CHANGE)
+	if p, ok := ifaces.P.(*int); !ok || p != &i || i != 3 {
+		logError(fmt.Sprintf("interfaces: P is %#v holding %d, expected &i holding 3", ifaces.P, i))
+	}
+	logInfo("end testInterface")
+}
+
+func testDerived(body jquery.JQuery) {
+	logInfo("begin testDerived")
+	order := struct {
+		Price    float64
+		Quantity int
+		Total    func() string `derive:"Price, Quantity"`
+	}{Price: 2.5, Quantity: 2}
+	order.Total = func() string {
+		return fmt.Sprintf("%.2f", order.Price*float64(order.Quantity))
+	}
+	j, e := htmlctrl.Struct(&order, "derived", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("derived: unexpected error: %s", e))
+	}
+	body.Append(j)
+	total := j.Find("output")
+	if text := total.Text(); text != "5.00" {
+		logError(fmt.Sprintf("derived: total is '%s', expected '5.00'", text))
+	}
+	j.Find("input").Eq(1).SetVal(4).Trigger(jquery.CHANGE)
+	if text := total.Text(); text != "10.00" {
+		logError(fmt.Sprintf("derived: total is '%s' after changing quantity, expected '10.00'", text))
+	}
+
+	unknown := struct {
+		A int
+		B func() string `derive:"C"`
+	}{B: func() string { return "" }}
+	_, e = htmlctrl.Struct(&unknown, "unknown dependency", "", "")
+	if e == nil {
+		logError("unknown dependency: expected error when dependency doesn't exist")
+	}
+	logInfo("end testDerived")
+}
+
+func testDescribeStruct(body jquery.JQuery) {
+	logInfo("begin testDescribeStruct")
+	described := struct {
+		I int
+		S string   `choice:"a,b" widget:"radio"`
+		L []string `widget:"lines"`
+		U uint
+		F float64 `widget:"bogus"`
+		p int
+		K func(int)
+	}{S: "a"}
+	descs, e := htmlctrl.DescribeStruct(&described)
+	if e != nil {
+		logError(fmt.Sprintf("describe: unexpected error: %s", e))
+	}
+	expected := []struct {
+		name, control string
+		rendered      bool
+	}{
+		{"I", "Int", true},
+		{"S", "Radio", true},
+		{"L", "SliceLines", true},
+		{"U", "", false},
+		{"F", "", false},
+		{"K", "", false},
+	}
+	if len(descs) != len(expected) {
+		logError(fmt.Sprintf("describe: got %d fields, expected %d", len(descs), len(expected)))
+		return
+	}
+	for i, exp := range expected {
+		d := descs[i]
+		if d.Name != exp.name || d.Control != exp.control || d.Rendered != exp.rendered {
+			logError(fmt.Sprintf("describe: field %d is %#v, expected %s rendered as '%s' (%v)", i, d, exp.name,
+				exp.control, exp.rendered))
+		}
+		if !d.Rendered && d.Reason == "" {
+			logError(fmt.Sprintf("describe: field %s isn't rendered but has no reason", d.Name))
+		}
+	}
+	_, e = htmlctrl.DescribeStruct(described)
+	if e == nil {
+		logError("describe: expected error for non-pointer")
+	}
+
+	nested := struct {
+		Chans []chan int
+		Bad   struct {
+			F float64 `widget:"bogus"`
+		}
+		Skips struct {
+			C chan int
+		}
+		R int           `render:"inline"`
+		T func() string `derive:"R, Missing"`
+	}{Chans: []chan int{make(chan int)}, T: func() string { return "" }}
+	descs, e = htmlctrl.DescribeStruct(&nested)
+	if e != nil {
+		logError(fmt.Sprintf("describe: unexpected error: %s", e))
+	}
+	reasons := []string{
+		"Chans[0]: unsupported type chan",
+		"Bad.F: unknown widget 'bogus' for float64",
+		"",
+		"render requires a struct, got int",
+		"derive has unknown field 'Missing'",
+	}
+	if len(descs) != len(reasons) {
+		logError(fmt.Sprintf("describe: got %d nested fields, expected %d", len(descs), len(reasons)))
+		return
+	}
+	for i, reason := range reasons {
+		if d := descs[i]; d.Reason != reason || d.Rendered != (reason == "") {
+			logError(fmt.Sprintf("describe: field %s has reason '%s', expected '%s'", d.Name, d.Reason, reason))
+		}
+	}
+	logInfo("end testDescribeStruct")
+}
+
+func testTypeValidator(body jquery.JQuery) {
+	logInfo("begin testTypeValidator")
+	htmlctrl.RegisterTypeValidator(reflect.String, htmlctrl.ValidateString(func(s string) bool {
+		return strings.TrimSpace(s) == s
+	}))
+	defer htmlctrl.RegisterTypeValidator(reflect.String, nil)
+	typed := struct {
+		S string
+		T string `valid:"nonEmpty"`
+	}{"a", "b"}
+	htmlctrl.RegisterValidator("nonEmpty", htmlctrl.ValidateString(func(s string) bool {
+		return s != ""
+	}))
+	j, e := htmlctrl.Struct(&typed, "type validator", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("type validator: unexpected error: %s", e))
+	}
+	body.Append(j)
+	inputs := j.Find("input")
+	inputs.Eq(0).SetVal(" b").Trigger(jquery.CHANGE)
+	if typed.S != "a" {
+		logError(fmt.Sprintf("type validator: S is '%s', expected change to ' b' to be rejected", typed.S))
+	}
+	inputs.Eq(1).SetVal(" c").Trigger(jquery.CHANGE)
+	if typed.T != " c" {
+		logError(fmt.Sprintf("type validator: T is '%s', expected valid tag to override the type validator",
+			typed.T))
+	}
+	logInfo("end testTypeValidator")
+}
+
+// color is edited as a single color input instead of three ints
+type color struct {
+	R, G, B int
+}
+
+func (c *color) MarshalControl(title, id, class string) (jquery.JQuery, error) {
+	j := jq("<input>").SetAttr("type", "color").AddClass(class)
+	j.SetAttr("title", title).SetAttr("id", id)
+	j.SetAttr("value", fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B))
+	j.Call(jquery.CHANGE, func(event jquery.Event) {
+		fmt.Sscanf(event.Target.Get("value").String(), "#%02x%02x%02x", &c.R, &c.G, &c.B)
+	})
+	return j, nil
+}
+
+func testControlMarshaler(body jquery.JQuery) {
+	logInfo("begin testControlMarshaler")
+	marshaled := struct {
+		C color `title:"pick a color"`
+	}{color{255, 0, 128}}
+	j, e := htmlctrl.Struct(&marshaled, "marshaler", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("marshaler: unexpected error: %s", e))
+	}
+	body.Append(j)
+	input := j.Find("input[type=color]")
+	if input.Length != 1 {
+		logError(fmt.Sprintf("marshaler: found %d color inputs, expected 1", input.Length))
+		return
+	}
+	if title := input.Attr("title"); title != "pick a color" {
+		logError(fmt.Sprintf("marshaler: title is '%s', expected 'pick a color'", title))
+	}
+	input.SetVal("#00ff10").Trigger(jquery.CHANGE)
+	if marshaled.C != (color{0, 255, 16}) {
+		logError(fmt.Sprintf("marshaler: color is %v, expected {0 255 16}", marshaled.C))
+	}
+	logInfo("end testControlMarshaler")
+}
+
+func testAutoID(body jquery.JQuery) {
+	logInfo("begin testAutoID")
+	type point struct {
+		X, Y int
+	}
+	nested := struct {
+		Name   string
+		Given  int `id:"given"`
+		Origin point
+		Points []point
+		Tags   []string
+	}{Points: []point{{1, 2}, {3, 4}}, Tags: []string{"a"}}
+	j, e := htmlctrl.StructAutoID(&nested, "auto id", "auto", "")
+	if e != nil {
+		logError(fmt.Sprintf("auto id: unexpected error: %s", e))
+	}
+	body.Append(j)
+	expected := []string{"auto", "auto-Name", "given", "auto-Origin", "auto-Origin-X", "auto-Origin-Y",
+		"auto-Points", "auto-Points-0", "auto-Points-0-X", "auto-Points-0-Y", "auto-Points-1", "auto-Points-1-X",
+		"auto-Points-1-Y", "auto-Tags", "auto-Tags-0"}
+	seen := map[string]bool{}
+	j.Find("[id]").Add(j).Each(func(i int, elem interface{}) {
+		id := jq(elem).Attr("id")
+		if seen[id] {
+			logError(fmt.Sprintf("auto id: id '%s' is used more than once", id))
+		}
+		seen[id] = true
+	})
+	for _, id := range expected {
+		if !seen[id] {
+			logError(fmt.Sprintf("auto id: expected an element with id '%s'", id))
+		}
+	}
+	if len(seen) != len(expected) {
+		logError(fmt.Sprintf("auto id: found %d ids, expected %d", len(seen), len(expected)))
+	}
+	if target := j.Find("label[for='auto-Name']").Length; target != 1 {
+		logError(fmt.Sprintf("auto id: found %d labels for auto-Name, expected 1", target))
+	}
+	_, e = htmlctrl.StructAutoID(&nested, "no id", "", "")
+	if e == nil {
+		logError("auto id: expected error without an id")
+	}
+	logInfo("end testAutoID")
+}
+
+func testRevertFocus(body jquery.JQuery) {
+	logInfo("begin testRevertFocus")
+	htmlctrl.RegisterValidator("nonEmpty", htmlctrl.ValidateString(func(s string) bool {
+		return s != ""
+	}))
+	focused := struct {
+		L []int    `max:"10"`
+		S []string `valid:"nonEmpty"`
+	}{[]int{1, 2}, []string{"abc"}}
+	j, e := htmlctrl.Struct(&focused, "revert focus", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("revert focus: unexpected error: %s", e))
+	}
+	body.Append(j)
+	document := js.Global.Get("document")
+	input := j.Find("input").Eq(1)
+	input.Focus()
+	input.SetVal(11).Trigger(jquery.CHANGE)
+	if focused.L[1] != 2 {
+		logError(fmt.Sprintf("revert focus: element is %d, expected 11 to be reverted to 2", focused.L[1]))
+	}
+	if document.Get("activeElement") != input.Get(0) {
+		logError("revert focus: expected focus to stay on the reverted int")
+	}
+	input = j.Find("input").Eq(2)
+	input.Focus()
+	input.SetVal("")
+	input.Get(0).Call("setSelectionRange", 0, 0)
+	input.Trigger(jquery.CHANGE)
+	if document.Get("activeElement") != input.Get(0) {
+		logError("revert focus: expected focus to stay on the reverted string")
+	}
+	if start := input.Get(0).Get("selectionStart").Int(); start != 0 {
+		logError(fmt.Sprintf("revert focus: caret is at %d, expected 0", start))
+	}
+	logInfo("end testRevertFocus")
+}
+
+func testCopyJSON(body jquery.JQuery) {
+	logInfo("begin testCopyJSON")
+	copied := struct {
+		A int
+		B string
+	}{1, "b"}
+	j, e := htmlctrl.StructCopyJSON(&copied, "copy json", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("copy json: unexpected error: %s", e))
+	}
+	body.Append(j)
+	if text := j.Find(".go-struct-copy").Text(); text != "Copy JSON" {
+		logError(fmt.Sprintf("copy json: button text is '%s', expected 'Copy JSON'", text))
+	}
+
+	unmarshalable := struct {
+		A int
+		F func() string `derive:"A"`
+	}{F: func() string { return "" }}
+	j, e = htmlctrl.StructCopyJSON(&unmarshalable, "copy json error", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("copy json error: unexpected error: %s", e))
+	}
+	body.Append(j)
+	j.Find(".go-struct-copy").Trigger(jquery.CLICK)
+	if text := j.Find(".go-struct-copy-error").Text(); text == "" {
+		logError("copy json error: expected the marshal error to be shown")
+	}
+	logInfo("end testCopyJSON")
+}
+
+type actionCounter struct {
+	N int
+}
+
+func (c *actionCounter) Reset() {
+	c.N = 0
+}
+
+func (c *actionCounter) Check() error {
+	if c.N == 0 {
+		return fmt.Errorf("N is 0")
+	}
+	return nil
+}
+
+func (c *actionCounter) Add(n int) {
+	c.N += n
+}
+
+func testActions(body jquery.JQuery) {
+	logInfo("begin testActions")
+	c := actionCounter{5}
+	j, e := htmlctrl.StructWithActions(&c, "actions", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("actions: unexpected error: %s", e))
+	}
+	body.Append(j)
+	buttons := j.Find(".go-struct-action")
+	if buttons.Length != 2 {
+		logError(fmt.Sprintf("actions: found %d buttons, expected 2", buttons.Length))
+		return
+	}
+	j.Find(".go-struct-action:contains('Check')").Trigger(jquery.CLICK)
+	if text := j.Find(".go-struct-action-error").Text(); text != "" {
+		logError(fmt.Sprintf("actions: unexpected error text '%s'", text))
+	}
+	j.Find(".go-struct-action:contains('Reset')").Trigger(jquery.CLICK)
+	if c.N != 0 {
+		logError(fmt.Sprintf("actions: N is %d, expected 0 after Reset", c.N))
+	}
+	if val := j.Find("input[type=number]").Val(); val != "0" {
+		logError(fmt.Sprintf("actions: input is '%s', expected '0' after Reset", val))
+	}
+	if n := j.Find("input").Length; n != 1 {
+		logError(fmt.Sprintf("actions: found %d inputs after Reset, expected 1", n))
+	}
+	j.Find(".go-struct-action:contains('Check')").Trigger(jquery.CLICK)
+	if text := j.Find(".go-struct-action-error").Text(); text != "N is 0" {
+		logError(fmt.Sprintf("actions: error text is '%s', expected 'N is 0'", text))
+	}
+	j.Find("input").SetVal("3").Trigger(jquery.CHANGE)
+	if c.N != 3 {
+		logError(fmt.Sprintf("actions: N is %d, expected the rebuilt input to set it to 3", c.N))
+	}
+	logInfo("end testActions")
+}
+
+func testOnReady(body jquery.JQuery) {
+	logInfo("begin testOnReady")
+	type inner struct{ B int }
+	nested := struct {
+		A     int
+		Inner inner
+		List  []inner
+	}{List: []inner{{1}, {2}}}
+	calls, inputs := 0, 0
+	ready := func(root jquery.JQuery) {
+		calls++
+		inputs = root.Find("input").Length
+	}
+	j, e := htmlctrl.Struct(&nested, "ready", "", "", htmlctrl.WithOnReady(ready))
+	if e != nil {
+		logError(fmt.Sprintf("ready: unexpected error: %s", e))
+	}
+	body.Append(j)
+	if calls != 1 {
+		logError(fmt.Sprintf("ready: called %d times, expected once for the top-level struct", calls))
+	}
+	if inputs != 4 {
+		logError(fmt.Sprintf("ready: found %d inputs when called, expected all 4 to be built", inputs))
+	}
+	logInfo("end testOnReady")
+}
+
+func testTime(body jquery.JQuery) {
+	logInfo("begin testTime")
+	start := time.Date(2021, 6, 1, 12, 30, 0, 0, time.Local)
+	dates := struct {
+		Start time.Time `min:"2021-01-01" max:"2021-12-31T23:59"`
+	}{start}
+	j, e := htmlctrl.Struct(&dates, "time", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("time: unexpected error: %s", e))
+	}
+	body.Append(j)
+	input := j.Find("input[type=datetime-local]")
+	if input.Length != 1 {
+		logError(fmt.Sprintf("time: found %d datetime-local inputs, expected 1", input.Length))
+		return
+	}
+	if val := input.Val(); val != "2021-06-01T12:30" {
+		logError(fmt.Sprintf("time: input is '%s', expected '2021-06-01T12:30'", val))
+	}
+	if min := input.Attr("min"); min != "2021-01-01T00:00" {
+		logError(fmt.Sprintf("time: min attribute is '%s', expected '2021-01-01T00:00'", min))
+	}
+	input.SetVal("2020-12-31T23:00").Trigger(jquery.CHANGE)
+	if !dates.Start.Equal(start) {
+		logError(fmt.Sprintf("time: Start is %s, expected a time before min to be rejected", dates.Start))
+	}
+	if val := input.Val(); val != "2021-06-01T12:30" {
+		logError(fmt.Sprintf("time: input is '%s', expected it to be reverted", val))
+	}
+	input.SetVal("2021-02-03T04:05").Trigger(jquery.CHANGE)
+	if expected := time.Date(2021, 2, 3, 4, 5, 0, 0, time.Local); !dates.Start.Equal(expected) {
+		logError(fmt.Sprintf("time: Start is %s, expected %s", dates.Start, expected))
+	}
+	input.SetVal("").Trigger(jquery.CHANGE)
+	if !dates.Start.IsZero() {
+		logError(fmt.Sprintf("time: Start is %s, expected the zero time when cleared", dates.Start))
+	}
+
+	badTag := struct {
+		T time.Time `min:"yesterday"`
+	}{}
+	_, e = htmlctrl.Struct(&badTag, "", "", "")
+	if e == nil {
+		logError("time: expected error for min that isn't a time")
+	}
+	badRange := time.Time{}
+	_, e = htmlctrl.Time(&badRange, "", "", "", start, start.Add(-time.Hour), nil)
+	if e == nil {
+		logError("time: expected error for min after max")
+	}
+	logInfo("end testTime")
+}
+
+func testValidateStruct(body jquery.JQuery) {
+	logInfo("begin testValidateStruct")
+	type item struct {
+		Name string `valid:"StringNotHello"`
+	}
+	form := struct {
+		I     int    `min:"0" max:"10"`
+		S     string `maxlen:"3"`
+		C     string `choice:"a,b"`
+		Items []item
+		Ptr   *item
+		Nil   *item
+	}{I: 11, S: "ok", C: "z", Items: []item{{"fine"}, {"hello"}}, Ptr: &item{"hello"}}
+	errs := htmlctrl.ValidateStruct(&form)
+	expected := []string{"I:", "C:", "Items[1].Name:", "Ptr.Name:"}
+	if len(errs) != len(expected) {
+		logError(fmt.Sprintf("validate struct: got errors %v, expected %d", errs, len(expected)))
+		return
+	}
+	for i, prefix := range expected {
+		if !strings.HasPrefix(errs[i].Error(), prefix) {
+			logError(fmt.Sprintf("validate struct: error %d is '%s', expected it to start with '%s'", i, errs[i], prefix))
+		}
+	}
+	form.I, form.C, form.Items[1].Name, form.Ptr.Name = 5, "a", "fine", "fine"
+	if errs := htmlctrl.ValidateStruct(&form); errs != nil {
+		logError(fmt.Sprintf("validate struct: unexpected errors %v", errs))
+	}
+	if errs := htmlctrl.ValidateStruct(form); len(errs) != 1 {
+		logError("validate struct: expected an error for non-pointer")
+	}
+	logInfo("end testValidateStruct")
+}
+
+func testRenderHooks(body jquery.JQuery) {
+	logInfo("begin testRenderHooks")
+	type item struct{ Name string }
+	form := struct {
+		A     int
+		Items []item
+		M     map[string]int
+	}{1, []item{{"x"}}, map[string]int{"k": 2}}
+	var before, after []string
+	hooks := htmlctrl.WithRenderHooks(func(path string) {
+		before = append(before, path)
+	}, func(path string, j jquery.JQuery) {
+		after = append(after, path)
+		j.SetAttr("data-path", path)
+	})
+	j, e := htmlctrl.Struct(&form, "hooks", "", "", hooks)
+	if e != nil {
+		logError(fmt.Sprintf("hooks: unexpected error: %s", e))
+	}
+	body.Append(j)
+	expectedBefore := []string{"A", "Items", "Items[0]", "Items[0].Name", "M", "M[k]"}
+	if !reflect.DeepEqual(before, expectedBefore) {
+		logError(fmt.Sprintf("hooks: before got %q, expected %q", before, expectedBefore))
+	}
+	// Each value is finished before the one containing it
+	expectedAfter := []string{"A", "Items[0].Name", "Items[0]", "Items", "M[k]", "M"}
+	if !reflect.DeepEqual(after, expectedAfter) {
+		logError(fmt.Sprintf("hooks: after got %q, expected %q", after, expectedAfter))
+	}
+	if n := j.Find("[data-path='Items[0].Name']").Length; n != 1 {
+		logError(fmt.Sprintf("hooks: found %d elements marked by after, expected 1", n))
+	}
+	logInfo("end testRenderHooks")
+}
+
+func testReadOnly(body jquery.JQuery) {
+	logInfo("begin testReadOnly")
+	when := time.Date(2020, 1, 2, 3, 4, 0, 0, time.Local)
+	form := struct {
+		Name  string
+		Count int
+		Mode  string  `choice:"a,b"`
+		List  []int   `valid:"IntNot0" addvalid:"true"`
+		Share float64 `widget:"percent"`
+		When  time.Time
+		Tags  []string `choice:"a,b" widget:"multiselect"`
+	}{"n", 1, "a", []int{0}, 0.5, when, []string{"a"}}
+	j, e := htmlctrl.Struct(&form, "read only", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("read only: unexpected error: %s", e))
+	}
+	body.Append(j)
+	addBtn := j.Find(".go-slice-add button")
+	if !addBtn.Prop("disabled").(bool) {
+		logError("read only: expected add button to start out disabled by addvalid")
+	}
+	// Inputs that aren't the package's own are left alone
+	own := jq("<button>").SetText("own")
+	j.Append(own)
+	htmlctrl.SetReadOnly(j, true)
+	if n := j.Find(":input:enabled").Length; n != 1 || !own.Is(":enabled") {
+		logError(fmt.Sprintf("read only: found %d enabled inputs, expected only the user's own button", n))
+	}
+	if !j.HasClass("go-readonly") {
+		logError("read only: expected the readonly class")
+	}
+	// Changes triggered from code aren't written back and the stored values are shown again
+	name := j.Find(".go-string")
+	name.SetVal("x").Trigger(jquery.CHANGE)
+	j.Find(".go-int").SetVal("5").Trigger(jquery.CHANGE)
+	if form.Name != "n" || form.Count != 1 {
+		logError(fmt.Sprintf("read only: form is %+v after changes while read-only, expected it to be unchanged", form))
+	}
+	if name.Val() != "n" {
+		logError(fmt.Sprintf("read only: Name input shows '%s', expected the stored 'n'", name.Val()))
+	}
+	share := j.Find(".go-percent input")
+	share.SetVal("80").Trigger(jquery.CHANGE)
+	if form.Share != 0.5 || share.Val() != "50" {
+		logError(fmt.Sprintf("read only: Share is %g shown as '%s', expected 0.5 shown as '50'", form.Share, share.Val()))
+	}
+	whenInput := j.Find(".go-time")
+	whenInput.SetVal("2021-05-06T07:08").Trigger(jquery.CHANGE)
+	if !form.When.Equal(when) || whenInput.Val() != "2020-01-02T03:04" {
+		logError(fmt.Sprintf("read only: When is %s shown as '%s', expected it to be unchanged", form.When,
+			whenInput.Val()))
+	}
+	tags := j.Find(".go-multiselect")
+	tagOptions := tags.Get(0).Get("options")
+	tagOptions.Index(1).Set("selected", true)
+	tags.Trigger(jquery.CHANGE)
+	if !reflect.DeepEqual(form.Tags, []string{"a"}) || tagOptions.Index(1).Get("selected").Bool() {
+		logError(fmt.Sprintf("read only: Tags is %v, expected [a] with only 'a' selected", form.Tags))
+	}
+	htmlctrl.SetReadOnly(j, false)
+	if n := j.Find(":input:disabled").Length; n != 1 {
+		logError(fmt.Sprintf("read only: found %d disabled inputs, expected only the add button", n))
+	}
+	if !addBtn.Prop("disabled").(bool) {
+		logError("read only: expected add button to still be disabled by addvalid")
+	}
+	name.SetVal("m").Trigger(jquery.CHANGE)
+	if form.Name != "m" {
+		logError(fmt.Sprintf("read only: Name is '%s', expected 'm' once editable again", form.Name))
+	}
+	logInfo("end testReadOnly")
+}
+
+func testSetter(body jquery.JQuery) {
+	logInfo("begin testSetter")
+	type item struct{ Name string }
+	form := struct {
+		A     int
+		Items []item
+		M     map[string]int
+	}{1, []item{{"x"}}, map[string]int{"k": 2}}
+	var paths []string
+	var values []interface{}
+	locked := false
+	setter := htmlctrl.WithSetter(func(path string, value interface{}, store func()) {
+		paths = append(paths, path)
+		values = append(values, value)
+		locked = true
+		store()
+		locked = false
+	})
+	j, e := htmlctrl.Struct(&form, "setter", "", "", setter)
+	if e != nil {
+		logError(fmt.Sprintf("setter: unexpected error: %s", e))
+	}
+	body.Append(j)
+	htmlctrl.OnChange(j, func(old, new interface{}) {
+		if locked {
+			logError("setter: change reported before store returned")
+		}
+	})
+	j.Find(".go-int").First().SetVal(5).Trigger(jquery.CHANGE)
+	j.Find(".go-slice .go-string").SetVal("y").Trigger(jquery.CHANGE)
+	j.Find(".go-map-entry input").SetVal(3).Trigger(jquery.CHANGE)
+	if form.A != 5 || form.Items[0].Name != "y" || form.M["k"] != 3 {
+		logError(fmt.Sprintf("setter: form is %+v, expected the new values to be stored", form))
+	}
+	expectedPaths := []string{"A", "Items[0].Name", "M[k]"}
+	expectedValues := []interface{}{5, "y", 3}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		logError(fmt.Sprintf("setter: got paths %q, expected %q", paths, expectedPaths))
+	}
+	if !reflect.DeepEqual(values, expectedValues) {
+		logError(fmt.Sprintf("setter: got values %v, expected %v", values, expectedValues))
+	}
+	logInfo("end testSetter")
+}
+
+func testAliasedPointers(body jquery.JQuery) {
+	logInfo("begin testAliasedPointers")
+	x := 1
+	s := []*int{&x, &x}
+	j, e := htmlctrl.Slice(&s, "aliased", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("aliased: unexpected error: %s", e))
+	}
+	body.Append(j)
+	inputs := j.Find(".go-int")
+	inputs.Eq(0).SetVal(5).Trigger(jquery.CHANGE)
+	if x != 5 {
+		logError(fmt.Sprintf("aliased: x is %d, expected 5", x))
+	}
+	if val := inputs.Eq(1).Val(); val != "5" {
+		logError(fmt.Sprintf("aliased: second element shows '%s', expected '5'", val))
+	}
+	x = 7
+	htmlctrl.Refresh(j)
+	inputs.Each(func(i int, elem interface{}) {
+		if val := jq(elem).Val(); val != "7" {
+			logError(fmt.Sprintf("aliased: element %d shows '%s' after Refresh, expected '7'", i, val))
+		}
+	})
+	logInfo("end testAliasedPointers")
+}
+
+func testValue(body jquery.JQuery) {
+	logInfo("begin testValue")
+	b, i := true, 3
+	st := struct{ A int }{1}
+	sl := []string{"a"}
+	cases := []struct {
+		name  string
+		ptr   interface{}
+		class string
+	}{
+		{"bool", &b, "go-bool"},
+		{"int", &i, "go-int"},
+		{"struct", &st, "go-struct"},
+		{"slice", &sl, "go-slice"},
+	}
+	values := jq("<div>").AddClass("values")
+	for _, c := range cases {
+		j, e := htmlctrl.Value(c.ptr, c.name, "", "")
+		if e != nil {
+			logError(fmt.Sprintf("value %s: unexpected error: %s", c.name, e))
+			continue
+		}
+		if !j.HasClass(c.class) {
+			logError(fmt.Sprintf("value %s: class is '%s', expected %s", c.name, j.Attr("class"), c.class))
+		}
+		if title := j.Attr("title"); title != c.name {
+			logError(fmt.Sprintf("value %s: title is %s, expected %s", c.name, title, c.name))
+		}
+		values.Append(j)
+	}
+	body.Append(values)
+
+	ch := make(chan int)
+	if _, e := htmlctrl.Value(&ch, "chan", "", ""); e == nil {
+		logError("value: expected error for unsupported type")
+	}
+	if _, e := htmlctrl.Value(i, "non-pointer", "", ""); e == nil {
+		logError("value: expected error for non-pointer")
+	}
+	logInfo("end testValue")
+}
+
+func testOptions(body jquery.JQuery) {
+	logInfo("begin testOptions")
+	i1, i2 := 4, 4
+	positional, e := htmlctrl.Int(&i1, "title", "options-int", "class", 0, 10, 2, nil)
+	if e != nil {
+		logError(fmt.Sprintf("options: unexpected error: %s", e))
+	}
+	withOptions, e := htmlctrl.Int(&i2, "", "", "", math.NaN(), math.NaN(), math.NaN(), nil,
+		htmlctrl.WithTitle("title"), htmlctrl.WithID("options-int"), htmlctrl.WithClass("class"),
+		htmlctrl.WithRange(0, 10), htmlctrl.WithStep(2))
+	if e != nil {
+		logError(fmt.Sprintf("options: unexpected error: %s", e))
+	}
+	for _, attr := range []string{"title", "id", "class", "min", "max", "step", "value"} {
+		if a, b := positional.Attr(attr), withOptions.Attr(attr); a != b {
+			logError(fmt.Sprintf("options: %s is '%s' with options, expected '%s'", attr, b, a))
+		}
+	}
+	withOptions.SetVal(11).Trigger(jquery.CHANGE)
+	if i2 != 4 {
+		logError(fmt.Sprintf("options: value is %d, expected 11 to be rejected by WithRange", i2))
+	}
+
+	s := "abc"
+	j, e := htmlctrl.String(&s, "", "", "", nil, htmlctrl.WithValidator(htmlctrl.ValidateString(func(s string) bool {
+		return s != "bad"
+	})), htmlctrl.WithLength(0, 5))
+	if e != nil {
+		logError(fmt.Sprintf("options: unexpected error: %s", e))
+	}
+	if maxLen := j.Attr("maxlength"); maxLen != "5" {
+		logError(fmt.Sprintf("options: maxlength is '%s', expected '5'", maxLen))
+	}
+	j.SetVal("bad").Trigger(jquery.CHANGE)
+	if s != "abc" {
+		logError(fmt.Sprintf("options: value is '%s', expected 'bad' to be rejected by WithValidator", s))
+	}
+	body.Append(positional).Append(withOptions).Append(j)
+	logInfo("end testOptions")
+}
+
+func testValidatorComposition(body jquery.JQuery) {
+	logInfo("begin testValidatorComposition")
+	even := htmlctrl.ValidateInt(func(i int) bool {
+		return i%2 == 0
+	})
+	isFive := htmlctrl.ValidateInt(func(i int) bool {
+		return i == 5
+	})
+	small := htmlctrl.ValidateInt(func(i int) bool {
+		return i < 10
+	})
+	cases := []struct {
+		name     string
+		valid    htmlctrl.Validator
+		accepted []int
+		rejected []int
+	}{
+		{"and", htmlctrl.And(even, small), []int{0, 8}, []int{5, 12}},
+		{"or", htmlctrl.Or(even, isFive), []int{4, 5}, []int{3, 7}},
+		{"not", htmlctrl.Not(isFive), []int{4, 6}, []int{5}},
+		{"not nil", htmlctrl.Not(nil), nil, []int{0, 5}},
+		{"nested", htmlctrl.And(small, htmlctrl.Or(even, isFive), htmlctrl.Not(isFive)), []int{2}, []int{5, 7, 12}},
+	}
+	for _, c := range cases {
+		for _, i := range c.accepted {
+			if !c.valid.Validate(i) {
+				logError(fmt.Sprintf("%s: expected %d to be accepted", c.name, i))
+			}
+		}
+		for _, i := range c.rejected {
+			if c.valid.Validate(i) {
+				logError(fmt.Sprintf("%s: expected %d to be rejected", c.name, i))
+			}
+		}
+	}
+
+	htmlctrl.RegisterValidator("smallEven", htmlctrl.And(even, small))
+	composed := struct {
+		I int `valid:"smallEven"`
+	}{2}
+	j, e := htmlctrl.Struct(&composed, "composed", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("composed: unexpected error: %s", e))
+	}
+	body.Append(j)
+	j.Find("input").SetVal(12).Trigger(jquery.CHANGE)
+	if composed.I != 2 {
+		logError(fmt.Sprintf("composed: value is %d, expected 12 to be rejected", composed.I))
+	}
+	logInfo("end testValidatorComposition")
+}
+
+func testBuiltinValidators(body jquery.JQuery) {
+	logInfo("begin testBuiltinValidators")
+	cases := []struct {
+		name     string
+		valid    htmlctrl.Validator
+		accepted []interface{}
+		rejected []interface{}
+	}{
+		{"NonEmptyString", htmlctrl.NonEmptyString, []interface{}{"a", " a "}, []interface{}{"", "  "}},
+		{"PositiveInt", htmlctrl.PositiveInt, []interface{}{1, 100}, []interface{}{0, -1}},
+		{"PositiveFloat64", htmlctrl.PositiveFloat64, []interface{}{0.1, 2.0}, []interface{}{0.0, -0.5}},
+		{"EmailString", htmlctrl.EmailString, []interface{}{"a@b.co", "first.last@mail.example.com"},
+			[]interface{}{"", "a@b", "@b.co", "a b@c.co", "a@b."}},
+		{"URLString", htmlctrl.URLString, []interface{}{"https://example.com", "http://a.b/c?d=e"},
+			[]interface{}{"", "example.com", "/path", "http://"}},
+	}
+	for _, c := range cases {
+		for _, v := range c.accepted {
+			if !c.valid.Validate(v) {
+				logError(fmt.Sprintf("%s: expected %#v to be accepted", c.name, v))
+			}
+		}
+		for _, v := range c.rejected {
+			if c.valid.Validate(v) {
+				logError(fmt.Sprintf("%s: expected %#v to be rejected", c.name, v))
+			}
+		}
+	}
+
+	htmlctrl.RegisterBuiltinValidators()
+	builtin := struct {
+		E string `valid:"EmailString"`
+		P int    `valid:"PositiveInt"`
+	}{"a@b.co", 1}
+	j, e := htmlctrl.Struct(&builtin, "builtin", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("builtin: unexpected error: %s", e))
+	}
+	body.Append(j)
+	inputs := j.Find("input")
+	inputs.Eq(0).SetVal("not an email").Trigger(jquery.CHANGE)
+	inputs.Eq(1).SetVal(-3).Trigger(jquery.CHANGE)
+	if builtin.E != "a@b.co" || builtin.P != 1 {
+		logError(fmt.Sprintf("builtin: values are %#v, expected invalid changes to be rejected", builtin))
+	}
+	logInfo("end testBuiltinValidators")
+}
+
+func testPrototype(body jquery.JQuery) {
+	logInfo("begin testPrototype")
+	type item struct {
+		Name string
+		Qty  int
+		Tags []string
+	}
+	proto := item{"new item", 1, []string{"a"}}
+	items := []*item{}
+	j, e := htmlctrl.Slice(&items, "prototype", "", "", math.NaN(), math.NaN(), math.NaN(), nil,
+		htmlctrl.WithPrototype(proto))
+	if e != nil {
+		logError(fmt.Sprintf("prototype: unexpected error: %s", e))
+	}
+	body.Append(j)
+	j.Children(".go-slice-add").Children("button").Trigger(jquery.CLICK)
+	j.Children(".go-slice-add").Children("button").Trigger(jquery.CLICK)
+	if len(items) != 2 {
+		logError(fmt.Sprintf("prototype: slice has %d elements, expected 2", len(items)))
+		return
+	}
+	for i, it := range items {
+		if !reflect.DeepEqual(*it, proto) {
+			logError(fmt.Sprintf("prototype: element %d is %#v, expected %#v", i, *it, proto))
+		}
+	}
+	items[0].Tags[0] = "changed"
+	if items[1].Tags[0] != "a" || proto.Tags[0] != "a" {
+		logError("prototype: elements share a slice with each other or the prototype")
+	}
+
+	_, e = htmlctrl.Slice(&items, "wrong prototype", "", "", math.NaN(), math.NaN(), math.NaN(), nil,
+		htmlctrl.WithPrototype(5))
+	if e == nil {
+		logError("prototype: expected error for prototype of the wrong type")
+	}
+	logInfo("end testPrototype")
+}
+
+func testMirror(body jquery.JQuery) {
+	logInfo("begin testMirror")
+	type settings struct {
+		Level int
+		Muted bool
+	}
+	volume := settings{5, false}
+	j, e := htmlctrl.Struct(&volume, "mirror", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("mirror: unexpected error: %s", e))
+	}
+	mirror := htmlctrl.Mirror(j, &volume, func(v interface{}) string {
+		return fmt.Sprintf("level %d", v.(settings).Level)
+	})
+	plain := htmlctrl.Mirror(j.Find("input").Eq(0), &volume.Level, nil)
+	body.Append(j).Append(mirror).Append(plain)
+	if text := mirror.Text(); text != "level 5" {
+		logError(fmt.Sprintf("mirror: text is '%s', expected 'level 5'", text))
+	}
+	j.Find("input").Eq(0).SetVal(8).Trigger(jquery.CHANGE)
+	if text := mirror.Text(); text != "level 8" {
+		logError(fmt.Sprintf("mirror: text is '%s' after change, expected 'level 8'", text))
+	}
+	if text := plain.Text(); text != "8" {
+		logError(fmt.Sprintf("mirror: plain text is '%s' after change, expected '8'", text))
+	}
+	logInfo("end testMirror")
+}
+
+func testNoNative(body jquery.JQuery) {
+	logInfo("begin testNoNative")
+	limited := struct {
+		I int     `min:"0" max:"10" step:"2"`
+		F float64 `min:"0" max:"1" step:"0.1"`
+		S string  `maxlen:"5"`
+	}{2, 0.5, "abc"}
+	j, e := htmlctrl.Struct(&limited, "no native", "", "", htmlctrl.WithoutNativeValidation())
+	if e != nil {
+		logError(fmt.Sprintf("no native: unexpected error: %s", e))
+	}
+	body.Append(j)
+	inputs := j.Find("input")
+	for i := 0; i < inputs.Length; i++ {
+		for _, attr := range []string{"min", "max", "maxlength"} {
+			if val := inputs.Eq(i).Attr(attr); val != "" {
+				logError(fmt.Sprintf("no native: input %d has %s '%s', expected none", i, attr, val))
+			}
+		}
+	}
+	if step := inputs.Eq(0).Attr("step"); step != "any" {
+		logError(fmt.Sprintf("no native: step is '%s', expected 'any'", step))
+	}
+	inputs.Eq(0).SetVal(12).Trigger(jquery.CHANGE)
+	if limited.I != 2 {
+		logError(fmt.Sprintf("no native: value is %d, expected max to still be enforced", limited.I))
+	}
+	logInfo("end testNoNative")
+}
+
+func testNoTypeClasses(body jquery.JQuery) {
+	logInfo("begin testNoTypeClasses")
+	form := struct {
+		A     int
+		B     string `class:"mine"`
+		Items []int
+	}{1, "b", []int{2}}
+	j, e := htmlctrl.Struct(&form, "no type classes", "", "", htmlctrl.WithoutTypeClasses())
+	if e != nil {
+		logError(fmt.Sprintf("no type classes: unexpected error: %s", e))
+	}
+	body.Append(j)
+	for _, class := range []string{"go-struct", "go-int", "go-string", "go-slice"} {
+		if n := j.Find("." + class).Add(j.Filter("." + class)).Length; n != 0 {
+			logError(fmt.Sprintf("no type classes: found %d elements with %s, expected none", n, class))
+		}
+	}
+	if n := j.Find("input.mine").Length; n != 1 {
+		logError(fmt.Sprintf("no type classes: found %d inputs with the class tag's class, expected 1", n))
+	}
+	if n := j.Find(".go-slice-add").Length; n != 1 {
+		logError(fmt.Sprintf("no type classes: found %d add rows, expected the slice's own classes to stay", n))
+	}
+	logInfo("end testNoTypeClasses")
+}
+
+func testValidatorProvider(body jquery.JQuery) {
+	logInfo("begin testValidatorProvider")
+	var current htmlctrl.Validator
+	provider := htmlctrl.ValidatorProvider(func() htmlctrl.Validator {
+		return current
+	})
+	i := 1
+	j, e := htmlctrl.Int(&i, "provider", "", "", math.NaN(), math.NaN(), math.NaN(), provider)
+	if e != nil {
+		logError(fmt.Sprintf("provider: unexpected error: %s", e))
+	}
+	body.Append(j)
+	cases := []struct {
+		valid    htmlctrl.Validator
+		entered  int
+		expected int
+	}{
+		{nil, 5, 5},
+		{htmlctrl.ValidateInt(func(i int) bool { return i > 10 }), 6, 5},
+		{htmlctrl.ValidateInt(func(i int) bool { return i > 10 }), 11, 11},
+		{htmlctrl.ValidateInt(func(i int) bool { return i < 10 }), 12, 11},
+		{htmlctrl.ValidateInt(func(i int) bool { return i < 10 }), 3, 3},
+	}
+	for n, c := range cases {
+		current = c.valid
+		j.SetVal(c.entered).Trigger(jquery.CHANGE)
+		if i != c.expected {
+			logError(fmt.Sprintf("provider: case %d stored %d, expected %d", n, i, c.expected))
+		}
+	}
+	// A nil provider is the same as a nil validator
+	var none htmlctrl.ValidatorProvider
+	j, e = htmlctrl.Int(&i, "provider nil", "", "", math.NaN(), math.NaN(), math.NaN(), none)
+	if e != nil {
+		logError(fmt.Sprintf("provider nil: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	j.SetVal(7).Trigger(jquery.CHANGE)
+	if i != 7 {
+		logError(fmt.Sprintf("provider nil: stored %d, expected 7", i))
+	}
+	logInfo("end testValidatorProvider")
+}
+
+func testTemplate(body jquery.JQuery) {
+	logInfo("begin testTemplate")
+	type address struct {
+		Street string
+		Zip    int `min:"0"`
+	}
+	t := htmlctrl.NewTemplate(reflect.TypeOf(address{}), htmlctrl.WithClass("address"))
+	home, work := address{"home st", 1}, address{"work st", 2}
+	homeCtrl, e := t.Instantiate(&home)
+	if e != nil {
+		logError(fmt.Sprintf("template: unexpected error: %s", e))
+		return
+	}
+	workCtrl, e := t.Instantiate(&work)
+	if e != nil {
+		logError(fmt.Sprintf("template: unexpected error: %s", e))
+		return
+	}
+	body.Append(homeCtrl.JQuery).Append(workCtrl.JQuery)
+	if homeCtrl.Ptr != &home || workCtrl.Ptr != &work {
+		logError("template: controls aren't bound to the given structs")
+	}
+	if !homeCtrl.JQuery.HasClass("address") || !workCtrl.JQuery.HasClass("address") {
+		logError("template: expected the template's options to apply to each control")
+	}
+	homeCtrl.JQuery.Find(".go-string").SetVal("new st").Trigger(jquery.CHANGE)
+	workCtrl.JQuery.Find(".go-int").SetVal(5).Trigger(jquery.CHANGE)
+	if home != (address{"new st", 1}) || work != (address{"work st", 5}) {
+		logError(fmt.Sprintf("template: got %+v and %+v, expected each control to change only its own struct",
+			home, work))
+	}
+	// The tag was parsed once but still applies to both
+	workCtrl.JQuery.Find(".go-int").SetVal(-1).Trigger(jquery.CHANGE)
+	if work.Zip != 5 {
+		logError(fmt.Sprintf("template: Zip is %d, expected -1 to be rejected by min", work.Zip))
+	}
+	other := struct{ A int }{}
+	if _, e := t.Instantiate(&other); e == nil {
+		logError("template: expected an error for a struct of another type")
+	}
+	logInfo("end testTemplate")
+}
+
+func testLogRange(body jquery.JQuery) {
+	logInfo("begin testLogRange")
+	audio := struct {
+		Freq   float64 `widget:"logrange" min:"20" max:"20000"`
+		Voices int     `widget:"logrange" min:"1" max:"1000"`
+	}{440, 10}
+	j, e := htmlctrl.Struct(&audio, "log range", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("log range: unexpected error: %s", e))
+	}
+	body.Append(j)
+	ranges := j.Find("input[type=range]")
+	if ranges.Length != 2 {
+		logError(fmt.Sprintf("log range: found %d ranges, expected 2", ranges.Length))
+		return
+	}
+	// The middle of a logarithmic slider is the geometric mean of min and max
+	ranges.Eq(0).SetVal(500).Trigger(jquery.CHANGE)
+	if math.Abs(audio.Freq-math.Sqrt(20*20000)) > 1e-6 {
+		logError(fmt.Sprintf("log range: freq is %v, expected %v", audio.Freq, math.Sqrt(20*20000)))
+	}
+	ranges.Eq(1).SetVal(1000).Trigger(jquery.CHANGE)
+	if audio.Voices != 1000 {
+		logError(fmt.Sprintf("log range: voices is %d, expected 1000", audio.Voices))
+	}
+	ranges.Eq(1).SetVal(0).Trigger(jquery.CHANGE)
+	if audio.Voices != 1 {
+		logError(fmt.Sprintf("log range: voices is %d, expected 1", audio.Voices))
+	}
+
+	bad := struct {
+		F float64 `widget:"logrange" min:"0" max:"10"`
+	}{}
+	if _, e := htmlctrl.Struct(&bad, "bad log range", "", ""); e == nil {
+		logError("log range: expected error for min of 0")
+	}
+	logInfo("end testLogRange")
+}
+
+func testSoftValidation(body jquery.JQuery) {
+	logInfo("begin testSoftValidation")
+	htmlctrl.RegisterBuiltinValidators()
+	contact := struct {
+		Email string `valid:"EmailString"`
+		Age   int    `min:"0"`
+	}{"a@b.co", 1}
+	j, e := htmlctrl.Struct(&contact, "soft", "", "", htmlctrl.WithSoftValidation())
+	if e != nil {
+		logError(fmt.Sprintf("soft: unexpected error: %s", e))
+	}
+	body.Append(j)
+	email := j.Find("input").Eq(0)
+	email.SetVal("half@typed").Trigger(jquery.CHANGE)
+	if val := email.Val(); val != "half@typed" {
+		logError(fmt.Sprintf("soft: input is '%s', expected the invalid entry to stay", val))
+	}
+	if contact.Email != "a@b.co" {
+		logError(fmt.Sprintf("soft: email is '%s', expected invalid entry not to be stored", contact.Email))
+	}
+	if !email.HasClass("go-invalid") || htmlctrl.FormValid(j) {
+		logError("soft: expected the input and form to be invalid")
+	}
+	email.SetVal("half@typed.co").Trigger(jquery.CHANGE)
+	if contact.Email != "half@typed.co" || email.HasClass("go-invalid") || !htmlctrl.FormValid(j) {
+		logError(fmt.Sprintf("soft: email is '%s', expected a valid entry to be stored and the form valid",
+			contact.Email))
+	}
+	logInfo("end testSoftValidation")
+}
+
+func testShiftStep(body jquery.JQuery) {
+	logInfo("begin testShiftStep")
+	// arrow presses the up or down arrow key on j, with Shift held if shift is true
+	arrow := func(j jquery.JQuery, up, shift bool) {
+		which := 40
+		if up {
+			which = 38
+		}
+		j.Trigger(js.Global.Get("jQuery").Call("Event", jquery.KEYDOWN, js.M{"which": which, "shiftKey": shift}))
+	}
+	i := 5
+	ji, e := htmlctrl.Int(&i, "shift step", "", "", 0, 20, 1, nil, htmlctrl.WithShiftStep(10))
+	if e != nil {
+		logError(fmt.Sprintf("shift step: unexpected error: %s", e))
+	}
+	body.Append(ji)
+	arrow(ji, true, true)
+	if i != 15 {
+		logError(fmt.Sprintf("shift step: i is %d, expected 15", i))
+	}
+	arrow(ji, true, true)
+	if i != 20 {
+		logError(fmt.Sprintf("shift step: i is %d, expected 20 to be the limit", i))
+	}
+	arrow(ji, false, false)
+	if i != 20 {
+		logError(fmt.Sprintf("shift step: i is %d, expected the arrow without Shift to be left to the browser", i))
+	}
+
+	f := 1.0
+	jf, e := htmlctrl.Float64(&f, "shift step float", "", "", math.NaN(), math.NaN(), 0.5, nil,
+		htmlctrl.WithShiftStep(4))
+	if e != nil {
+		logError(fmt.Sprintf("shift step float: unexpected error: %s", e))
+	}
+	body.Append(jf)
+	arrow(jf, false, true)
+	if f != -1 {
+		logError(fmt.Sprintf("shift step float: f is %v, expected -1", f))
+	}
+	logInfo("end testShiftStep")
+}
+
+func testKeyFilter(body jquery.JQuery) {
+	logInfo("begin testKeyFilter")
+	i, f := 0, 0.0
+	ji, e := htmlctrl.Int(&i, "key filter int", "", "", math.NaN(), math.NaN(), math.NaN(), nil,
+		htmlctrl.WithKeyFilter())
+	if e != nil {
+		logError(fmt.Sprintf("key filter: unexpected error: %s", e))
+	}
+	jf, e := htmlctrl.Float64(&f, "key filter float64", "", "", math.NaN(), math.NaN(), math.NaN(), nil,
+		htmlctrl.WithKeyFilter())
+	if e != nil {
+		logError(fmt.Sprintf("key filter: unexpected error: %s", e))
+	}
+	body.Append(ji).Append(jf)
+	// keyPrevented reports whether typing c into j is blocked
+	keyPrevented := func(j jquery.JQuery, c rune) bool {
+		event := js.Global.Get("jQuery").Call("Event", jquery.KEYPRESS, js.M{"which": int(c)})
+		j.Trigger(event)
+		return event.Call("isDefaultPrevented").Bool()
+	}
+	cases := []struct {
+		name    string
+		j       jquery.JQuery
+		allowed string
+		blocked string
+	}{
+		{"int", ji, "0123456789-+", "a.e,"},
+		{"float64", jf, "0123456789-+.eE", "ax,"},
+	}
+	for _, c := range cases {
+		for _, r := range c.allowed {
+			if keyPrevented(c.j, r) {
+				logError(fmt.Sprintf("key filter %s: expected '%c' to be allowed", c.name, r))
+			}
+		}
+		for _, r := range c.blocked {
+			if !keyPrevented(c.j, r) {
+				logError(fmt.Sprintf("key filter %s: expected '%c' to be blocked", c.name, r))
+			}
+		}
+	}
+	logInfo("end testKeyFilter")
+}
+
+func testDecimals(body jquery.JQuery) {
+	logInfo("begin testDecimals")
+	form := struct {
+		Price float64 `decimals:"2" min:"0"`
+	}{1}
+	j, e := htmlctrl.Struct(&form, "decimals", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("decimals: unexpected error: %s", e))
+	}
+	body.Append(j)
+	input := j.Find(".go-float64")
+	cases := []struct {
+		entered  string
+		expected float64
+	}{
+		{"2.5", 2.5},
+		{"2.55", 2.55},
+		{"2.555", 2.55},
+		{"3.100", 3.1},
+		{"1.234e1", 3.1},
+		{"1.23e1", 12.3},
+		{"-1.5", 12.3},
+	}
+	for _, c := range cases {
+		input.SetVal(c.entered).Trigger(jquery.CHANGE)
+		if form.Price != c.expected {
+			logError(fmt.Sprintf("decimals: entering %s stored %v, expected %v", c.entered, form.Price, c.expected))
+		}
+	}
+	form.Price = 1.001
+	if errs := htmlctrl.ValidateStruct(&form); len(errs) != 1 {
+		logError(fmt.Sprintf("decimals: ValidateStruct returned %v, expected one error", errs))
+	}
+	logInfo("end testDecimals")
+}
+
+func testSign(body jquery.JQuery) {
+	logInfo("begin testSign")
+	form := struct {
+		P  int     `positive:"true"`
+		N  float64 `nonnegative:"true"`
+		PM int     `positive:"true" min:"-5"`
+	}{1, 1, 1}
+	j, e := htmlctrl.Struct(&form, "sign", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("sign: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	inputs := j.Find("input")
+	cases := []struct {
+		field    int
+		entered  string
+		expected interface{}
+	}{
+		{0, "-1", 1},
+		{0, "0", 1},
+		{0, "3", 3},
+		{1, "-0.5", 1.0},
+		{1, "0", 0.0},
+		{1, "2.5", 2.5},
+		{2, "-2", 1},
+		{2, "4", 4},
+	}
+	for _, c := range cases {
+		inputs.Eq(c.field).SetVal(c.entered).Trigger(jquery.CHANGE)
+		got := []interface{}{form.P, form.N, form.PM}[c.field]
+		if got != c.expected {
+			logError(fmt.Sprintf("sign: entering %s into field %d stored %v, expected %v", c.entered, c.field, got,
+				c.expected))
+		}
+	}
+	logInfo("end testSign")
+}
+
+func testCommitMode(body jquery.JQuery) {
+	logInfo("begin testCommitMode")
+	type form struct {
+		S string `minlen:"2"`
+		I int
+		F float64
+	}
+	setAll := func(j jquery.JQuery, event string) {
+		j.Find(".go-string").SetVal("ab").Trigger(event)
+		j.Find(".go-int").SetVal(2).Trigger(event)
+		j.Find(".go-float64").SetVal(2.5).Trigger(event)
+	}
+	cases := []struct {
+		mode          string
+		storedBy      string
+		notStoredBy   string
+		expectedError bool
+	}{
+		{"change", jquery.CHANGE, jquery.BLUR, false},
+		{"blur", jquery.BLUR, jquery.CHANGE, false},
+		{"input", "input", jquery.BLUR, false},
+		{"keyup", "", "", true},
+	}
+	for _, c := range cases {
+		f := form{"a", 1, 1.5}
+		j, e := htmlctrl.Struct(&f, "commit "+c.mode, "", "", htmlctrl.WithCommitMode(c.mode))
+		if c.expectedError {
+			if e == nil {
+				logError(fmt.Sprintf("commit %s: expected an error", c.mode))
+			}
+			continue
+		}
+		if e != nil {
+			logError(fmt.Sprintf("commit %s: unexpected error: %s", c.mode, e))
+			continue
+		}
+		body.Append(j)
+		setAll(j, c.notStoredBy)
+		if f != (form{"a", 1, 1.5}) {
+			logError(fmt.Sprintf("commit %s: %s stored %+v, expected nothing", c.mode, c.notStoredBy, f))
+		}
+		setAll(j, c.storedBy)
+		if f != (form{"ab", 2, 2.5}) {
+			logError(fmt.Sprintf("commit %s: %s stored %+v, expected {ab 2 2.5}", c.mode, c.storedBy, f))
+		}
+	}
+
+	// While typing an invalid value is left alone until the input loses focus
+	f := form{"ab", 1, 1.5}
+	j, e := htmlctrl.Struct(&f, "commit input", "", "", htmlctrl.WithCommitMode("input"))
+	if e != nil {
+		logError(fmt.Sprintf("commit input: unexpected error: %s", e))
+	}
+	body.Append(j)
+	input := j.Find(".go-string")
+	input.SetVal("a").Trigger("input")
+	if f.S != "ab" || input.Val() != "a" {
+		logError(fmt.Sprintf("commit input: S is '%s' showing '%s', expected 'ab' showing 'a'", f.S, input.Val()))
+	}
+	input.Trigger(jquery.CHANGE)
+	if input.Val() != "ab" {
+		logError(fmt.Sprintf("commit input: showing '%s' after the change, expected it reverted to 'ab'", input.Val()))
+	}
+	logInfo("end testCommitMode")
+}
+
+func testStructFields(body jquery.JQuery) {
+	logInfo("begin testStructFields")
+	wizard := struct {
+		Name  string
+		Email string
+		Age   int
+		Admin bool
+	}{"n", "e", 1, false}
+	page1, e := htmlctrl.StructFields(&wizard, []string{"Email", "Name"}, "page 1", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("page 1: unexpected error: %s", e))
+	}
+	page2, e := htmlctrl.StructFields(&wizard, []string{"Age"}, "page 2", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("page 2: unexpected error: %s", e))
+	}
+	body.Append(page1).Append(page2)
+	labels := page1.Find("label")
+	if labels.Length != 2 || labels.Eq(0).Text() != "Email" || labels.Eq(1).Text() != "Name" {
+		logError(fmt.Sprintf("page 1: found %d fields starting with '%s', expected Email then Name", labels.Length,
+			labels.Eq(0).Text()))
+	}
+	if n := page2.Find("input").Length; n != 1 {
+		logError(fmt.Sprintf("page 2: found %d inputs, expected 1", n))
+	}
+	page1.Find("input").Eq(0).SetVal("a@b.co").Trigger(jquery.CHANGE)
+	page2.Find("input").SetVal(30).Trigger(jquery.CHANGE)
+	if wizard.Email != "a@b.co" || wizard.Age != 30 {
+		logError(fmt.Sprintf("pages: value is %#v, expected Email a@b.co and Age 30", wizard))
+	}
+	_, e = htmlctrl.StructFields(&wizard, []string{"Nope"}, "unknown", "", "")
+	if e == nil {
+		logError("unknown: expected error for unknown field name")
+	}
+	logInfo("end testStructFields")
+}
+
+func testMap(body jquery.JQuery) {
+	logInfo("begin testMap")
+	scores := map[string]int{"carol": 3, "alice": 1, "dave": 4, "bob": 2}
+	keysOf := func(j jquery.JQuery) []string {
+		var keys []string
+		j.Find(".go-map-entry > label").Each(func(i int, elem interface{}) {
+			keys = append(keys, jq(elem).Text())
+		})
+		return keys
+	}
+	first, e := htmlctrl.Map(&scores, "map", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("map: unexpected error: %s", e))
+	}
+	second, e := htmlctrl.Map(&scores, "map again", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("map: unexpected error: %s", e))
+	}
+	body.Append(first).Append(second)
+	expected := []string{"alice", "bob", "carol", "dave"}
+	if keys := keysOf(first); !reflect.DeepEqual(keys, expected) {
+		logError(fmt.Sprintf("map: keys are %v, expected %v", keys, expected))
+	}
+	if keys := keysOf(second); !reflect.DeepEqual(keys, keysOf(first)) {
+		logError(fmt.Sprintf("map: keys are %v the second time, expected %v", keys, keysOf(first)))
+	}
+
+	first.Find(".go-map-entry input").Eq(1).SetVal(20).Trigger(jquery.CHANGE)
+	if scores["bob"] != 20 {
+		logError(fmt.Sprintf("map: bob is %d, expected 20", scores["bob"]))
+	}
+	first.Find(".go-map-key").SetVal("aaron")
+	first.Find(".go-map-new button").Trigger(jquery.CLICK)
+	if v, ok := scores["aaron"]; !ok || v != 0 {
+		logError(fmt.Sprintf("map: aaron is %d (%v), expected a new entry of 0", v, ok))
+	}
+	if keys := keysOf(first); len(keys) != 5 || keys[0] != "aaron" {
+		logError(fmt.Sprintf("map: keys are %v, expected aaron to be first", keys))
+	}
+	first.Find(".go-map-entry").Eq(0).Children("button").Trigger(jquery.CLICK)
+	if _, ok := scores["aaron"]; ok {
+		logError("map: expected aaron to be deleted")
+	}
+	logInfo("end testMap")
+}
+
+func testMapOfStructs(body jquery.JQuery) {
+	logInfo("begin testMapOfStructs")
+	type address struct {
+		Street string
+		Zip    int `min:"0"`
+	}
+	addresses := map[string]address{"home": {"1 main", 10}, "work": {"2 side", 20}}
+	j, e := htmlctrl.Map(&addresses, "map of structs", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("map of structs: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	entries := j.Find(".go-map-entry")
+	if n := entries.Find(".go-struct").Length; n != 2 {
+		logError(fmt.Sprintf("map of structs: found %d structs, expected 2", n))
+	}
+	work := entries.Eq(1)
+	work.Find(".go-string").SetVal("3 other").Trigger(jquery.CHANGE)
+	work.Find(".go-int").SetVal(30).Trigger(jquery.CHANGE)
+	if addresses["work"] != (address{"3 other", 30}) || addresses["home"] != (address{"1 main", 10}) {
+		logError(fmt.Sprintf("map of structs: map is %+v, expected only work to change", addresses))
+	}
+	work.Find(".go-int").SetVal(-1).Trigger(jquery.CHANGE)
+	if addresses["work"].Zip != 30 {
+		logError(fmt.Sprintf("map of structs: Zip is %d, expected -1 to be rejected", addresses["work"].Zip))
+	}
+	j.Find(".go-map-key").SetVal("cabin")
+	j.Find(".go-map-new button").Trigger(jquery.CLICK)
+	if v, ok := addresses["cabin"]; !ok || v != (address{}) {
+		logError(fmt.Sprintf("map of structs: cabin is %+v (%v), expected a new zero entry", v, ok))
+	}
+	cabin := j.Find(".go-map-entry").Eq(0)
+	cabin.Find(".go-string").SetVal("4 lake").Trigger(jquery.CHANGE)
+	if addresses["cabin"].Street != "4 lake" {
+		logError(fmt.Sprintf("map of structs: cabin is %+v, expected the new entry to be editable", addresses["cabin"]))
+	}
+	logInfo("end testMapOfStructs")
+}
+
+func testCallbacks(body jquery.JQuery) {
+	logInfo("begin testCallbacks")
+	saved := 0
+	form := struct {
+		Name string
+		Save func()
+		Load func(string) error
+		None func()
+	}{Name: "a", Save: func() { saved++ }}
+	j, e := htmlctrl.Struct(&form, "callbacks", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("callbacks: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	buttons := j.Find("button.go-callback")
+	if buttons.Length != 2 {
+		logError(fmt.Sprintf("callbacks: found %d buttons, expected 2 with Load skipped", buttons.Length))
+		return
+	}
+	if text := buttons.Eq(0).Text(); text != "Save" {
+		logError(fmt.Sprintf("callbacks: button says '%s', expected 'Save'", text))
+	}
+	buttons.Eq(0).Trigger(jquery.CLICK)
+	if saved != 1 {
+		logError(fmt.Sprintf("callbacks: Save ran %d times, expected 1", saved))
+	}
+	// The func is read when clicked so it can be replaced
+	form.Save = func() { saved += 10 }
+	buttons.Eq(0).Trigger(jquery.CLICK)
+	if saved != 11 {
+		logError(fmt.Sprintf("callbacks: saved is %d, expected the replaced func to run", saved))
+	}
+	if !buttons.Eq(1).Prop("disabled").(bool) {
+		logError("callbacks: expected the button for a nil func to be disabled")
+	}
+	buttons.Eq(1).Trigger(jquery.CLICK)
+	descs, e := htmlctrl.DescribeStruct(&form)
+	if e != nil || len(descs) != 3 || descs[1].Control != "callback" {
+		logError(fmt.Sprintf("callbacks: described as %+v (%v), expected Load to be left out", descs, e))
+	}
+	logInfo("end testCallbacks")
+}
+
+func testFormatted(body jquery.JQuery) {
+	logInfo("begin testFormatted")
+	form := struct {
+		I int     `widget:"formatted"`
+		F float64 `widget:"formatted"`
+	}{1234567, -1234.5}
+	j, e := htmlctrl.Struct(&form, "formatted", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("formatted: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	i, f := j.Find(".go-int"), j.Find(".go-float64")
+	if i.Val() != "1,234,567" || f.Val() != "-1,234.5" {
+		logError(fmt.Sprintf("formatted: showing '%s' and '%s', expected '1,234,567' and '-1,234.5'", i.Val(), f.Val()))
+	}
+	i.Trigger(jquery.FOCUS)
+	if i.Val() != "1234567" {
+		logError(fmt.Sprintf("formatted: showing '%s' with focus, expected '1234567'", i.Val()))
+	}
+	i.SetVal("2,000").Trigger(jquery.CHANGE).Trigger(jquery.BLUR)
+	if form.I != 2000 || i.Val() != "2,000" {
+		logError(fmt.Sprintf("formatted: I is %d showing '%s', expected 2000 showing '2,000'", form.I, i.Val()))
+	}
+	f.Trigger(jquery.FOCUS).SetVal("1000000.25").Trigger(jquery.CHANGE).Trigger(jquery.BLUR)
+	if form.F != 1000000.25 || f.Val() != "1,000,000.25" {
+		logError(fmt.Sprintf("formatted: F is %v showing '%s', expected 1000000.25 showing '1,000,000.25'", form.F,
+			f.Val()))
+	}
+	i.Trigger(jquery.FOCUS).SetVal("lots").Trigger(jquery.CHANGE).Trigger(jquery.BLUR)
+	if form.I != 2000 || i.Val() != "2,000" {
+		logError(fmt.Sprintf("formatted: I is %d showing '%s' after 'lots', expected it reverted", form.I, i.Val()))
+	}
+	form.I = 5000
+	htmlctrl.Refresh(j)
+	if i.Val() != "5,000" {
+		logError(fmt.Sprintf("formatted: showing '%s' after Refresh, expected '5,000'", i.Val()))
+	}
+	logInfo("end testFormatted")
+}
+
+func testErrorPaths(body jquery.JQuery) {
+	logInfo("begin testErrorPaths")
+	type sub struct {
+		Items []interface{}
+	}
+	type badTag struct {
+		N int `min:"x"`
+	}
+	cases := []struct {
+		name     string
+		ptr      interface{}
+		expected string
+	}{
+		{"slice", &struct{ Sub sub }{sub{[]interface{}{1, "a", make(chan int)}}}, "Sub.Items[2]: unsupported type chan"},
+		{"map", &struct{ M map[string]interface{} }{map[string]interface{}{"k": make(chan int)}},
+			"M[k]: unsupported type chan"},
+		{"tag", &struct{ Sub badTag }{}, "Sub.N: min as value 'x' expected a number or a time like '2006-01-02T15:04'"},
+	}
+	for _, c := range cases {
+		_, e := htmlctrl.Struct(c.ptr, "error paths", "", "")
+		if e == nil || e.Error() != c.expected {
+			logError(fmt.Sprintf("error paths: %s got error '%v', expected '%s'", c.name, e, c.expected))
+		}
+	}
+	logInfo("end testErrorPaths")
+}
+
+func testFindByType(body jquery.JQuery) {
+	logInfo("begin testFindByType")
+	type inner struct {
+		On bool
+	}
+	b := true
+	form := struct {
+		A     bool
+		B     *bool
+		I     int
+		Sub   inner
+		Items []bool
+		Any   interface{}
+	}{true, &b, 1, inner{false}, []bool{true, false}, false}
+	j, e := htmlctrl.Struct(&form, "find by type", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("find by type: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	cases := []struct {
+		kind     reflect.Kind
+		expected int
+	}{
+		{reflect.Bool, 6},
+		{reflect.Int, 1},
+		{reflect.Struct, 1},
+		{reflect.Slice, 1},
+		{reflect.String, 0},
+	}
+	for _, c := range cases {
+		if found := htmlctrl.FindByType(j, c.kind); len(found) != c.expected {
+			logError(fmt.Sprintf("find by type: found %d %s controls, expected %d", len(found), c.kind, c.expected))
+		}
+	}
+	for _, box := range htmlctrl.FindByType(j, reflect.Bool) {
+		box.SetProp("disabled", true)
+	}
+	if n := j.Find("input[type=checkbox]:enabled").Length; n != 0 {
+		logError(fmt.Sprintf("find by type: %d checkboxes still enabled, expected every bool to be found", n))
+	}
+	i := 1
+	ji, e := htmlctrl.Value(&i, "", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("find by type: unexpected error: %s", e))
+	}
+	if found := htmlctrl.FindByType(ji, reflect.Int); len(found) != 1 {
+		logError(fmt.Sprintf("find by type: found %d controls made by Value, expected the root", len(found)))
+	}
+	logInfo("end testFindByType")
+}
+
+func testBoolButtons(body jquery.JQuery) {
+	logInfo("begin testBoolButtons")
+	form := struct {
+		Agree bool `widget:"buttons"`
+	}{}
+	j, e := htmlctrl.Struct(&form, "bool buttons", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("bool buttons: unexpected error: %s", e))
+		return
+	}
+	styled := struct {
+		Sure bool `style:"buttons"`
+	}{}
+	js, e := htmlctrl.Struct(&styled, "bool buttons", "", "")
+	if e != nil || js.Find(".go-bool-buttons").Length != 1 {
+		logError(fmt.Sprintf("bool buttons: style tag gave error %v, expected a pair of buttons", e))
+	}
+	badStyle := struct {
+		B bool `style:"switch"`
+	}{}
+	if _, e := htmlctrl.Struct(&badStyle, "bool buttons", "", ""); e == nil {
+		logError("bool buttons: expected error for an unknown style")
+	}
+	body.Append(j)
+	buttons := j.Find(".go-bool-buttons button")
+	if buttons.Length != 2 || buttons.Eq(0).Text() != "Yes" || buttons.Eq(1).Text() != "No" {
+		logError(fmt.Sprintf("bool buttons: found %d buttons, expected Yes and No", buttons.Length))
+		return
+	}
+	selected := func() string {
+		return j.Find(".go-bool-selected").Text()
+	}
+	if selected() != "No" {
+		logError(fmt.Sprintf("bool buttons: '%s' is highlighted, expected 'No'", selected()))
+	}
+	buttons.Eq(0).Trigger(jquery.CLICK)
+	if !form.Agree || selected() != "Yes" {
+		logError(fmt.Sprintf("bool buttons: Agree is %v with '%s' highlighted, expected true and 'Yes'", form.Agree,
+			selected()))
+	}
+	buttons.Eq(1).Trigger(jquery.CLICK)
+	if form.Agree || selected() != "No" {
+		logError(fmt.Sprintf("bool buttons: Agree is %v with '%s' highlighted, expected false and 'No'", form.Agree,
+			selected()))
+	}
+
+	b := false
+	onlyTrue := htmlctrl.ValidateBool(func(b bool) bool { return b })
+	jb, e := htmlctrl.BoolButtons(&b, "", "", "", onlyTrue)
+	if e != nil {
+		logError(fmt.Sprintf("bool buttons: unexpected error: %s", e))
+	}
+	body.Append(jb)
+	jb.Find("button").Eq(0).Trigger(jquery.CLICK)
+	jb.Find("button").Eq(1).Trigger(jquery.CLICK)
+	if !b || jb.Find(".go-bool-selected").Text() != "Yes" {
+		logError(fmt.Sprintf("bool buttons: b is %v, expected No to be rejected by the validator", b))
+	}
+	logInfo("end testBoolButtons")
+}
+
+func testOnChange(body jquery.JQuery) {
+	logInfo("begin testOnChange")
+	changes := struct {
+		I int
+		F float64
+		S string
+		L []int
+	}{1, 1.5, "a", []int{1}}
+	j, e := htmlctrl.Struct(&changes, "changes", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("changes: unexpected error: %s", e))
+	}
+	var olds, news []interface{}
+	htmlctrl.OnChange(j, func(old, new interface{}) {
+		olds, news = append(olds, old), append(news, new)
+	})
+	inputs := j.Find("input")
+	inputs.Eq(0).SetVal(2).Trigger(jquery.CHANGE)
+	inputs.Eq(1).SetVal(2.5).Trigger(jquery.CHANGE)
+	inputs.Eq(2).SetVal("b").Trigger(jquery.CHANGE)
+	j.Find(".go-slice-add").Children("button").Trigger(jquery.CLICK)
+	expected := []struct {
+		old, new interface{}
+	}{
+		{1, 2},
+		{1.5, 2.5},
+		{"a", "b"},
+		{[]int{1}, []int{1, 0}},
+	}
+	if len(olds) != len(expected) {
+		logError(fmt.Sprintf("changes: got %d changes, expected %d", len(olds), len(expected)))
+		return
+	}
+	for i, exp := range expected {
+		if !reflect.DeepEqual(olds[i], exp.old) || !reflect.DeepEqual(news[i], exp.new) {
+			logError(fmt.Sprintf("changes: change %d was %#v -> %#v, expected %#v -> %#v", i, olds[i], news[i],
+				exp.old, exp.new))
+		}
+	}
+	logInfo("end testOnChange")
+}
+
+func testPointerToPointer(body jquery.JQuery) {
+	logInfo("begin testPointerToPointer")
+	str := "a"
+	ps := &str
+	pps := &ps
+	form := struct {
+		I **int
+		S ***string
+	}{nil, &pps}
+	j, e := htmlctrl.Struct(&form, "pointers", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("pointers: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	if form.I != nil {
+		logError("pointers: nil pointer to pointer was allocated before anything was stored")
+	}
+	changed := 0
+	htmlctrl.OnChange(j, func(old, new interface{}) {
+		changed++
+	})
+	j.Find(".go-int").SetVal(5).Trigger(jquery.CHANGE)
+	if form.I == nil || *form.I == nil || **form.I != 5 {
+		logError("pointers: int wasn't stored through the pointers")
+	}
+	if changed != 1 {
+		logError(fmt.Sprintf("pointers: OnChange was called %d times for the first store, expected 1", changed))
+	}
+	j.Find(".go-string").SetVal("abc").Trigger(jquery.CHANGE)
+	if str != "abc" {
+		logError(fmt.Sprintf("pointers: string is '%s', expected 'abc'", str))
+	}
+	logInfo("end testPointerToPointer")
+}
+
+func testSliceDrag(body jquery.JQuery) {
+	logInfo("begin testSliceDrag")
+	form := struct {
+		L []int `widget:"draggable"`
+	}{[]int{1, 2, 3, 4}}
+	j, e := htmlctrl.Struct(&form, "drag", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("drag: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	cases := []struct {
+		from, to int
+		expected []int
+	}{
+		{0, 2, []int{2, 3, 1, 4}},
+		{3, 0, []int{4, 2, 3, 1}},
+		{1, 1, []int{4, 2, 3, 1}},
+	}
+	for _, c := range cases {
+		items := j.Find(".go-slice-drag")
+		if items.Length != len(form.L) {
+			logError(fmt.Sprintf("drag: %d draggable items, expected %d", items.Length, len(form.L)))
+			return
+		}
+		if items.Eq(0).Attr("draggable") != "true" {
+			logError("drag: items aren't draggable")
+		}
+		items.Eq(c.from).Trigger("dragstart")
+		items.Eq(c.to).Trigger("drop")
+		if fmt.Sprint(form.L) != fmt.Sprint(c.expected) {
+			logError(fmt.Sprintf("drag: dropping %d onto %d gave %v, expected %v", c.from, c.to, form.L, c.expected))
+		}
+	}
+	logInfo("end testSliceDrag")
+}
+
+func testTabIndex(body jquery.JQuery) {
+	logInfo("begin testTabIndex")
+	form := struct {
+		A int    `tabindex:"2"`
+		B string `tabindex:"1"`
+		C bool
+		D []int `tabindex:"3"`
+	}{}
+	j, e := htmlctrl.Struct(&form, "tabindex", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("tabindex: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	cases := []struct {
+		selector string
+		expected string
+	}{
+		{".go-int", "2"},
+		{".go-string", "1"},
+		{".go-bool", ""},
+	}
+	for _, c := range cases {
+		if got := j.Find(c.selector).Attr("tabindex"); got != c.expected {
+			logError(fmt.Sprintf("tabindex: %s has tabindex '%s', expected '%s'", c.selector, got, c.expected))
+		}
+	}
+	// Slices redo their contents when an element is added, which shouldn't lose the tabindex
+	j.Find(".go-slice-add button").Trigger(jquery.CLICK)
+	slice := j.Find(".go-slice")
+	if n := slice.Find(".go-int").Length; n != 1 {
+		logError(fmt.Sprintf("tabindex: slice has %d elements after add, expected 1", n))
+	}
+	if n := slice.Find(":input:not([tabindex='3'])").Length; n != 0 {
+		logError(fmt.Sprintf("tabindex: %d inputs of the slice lost their tabindex after add", n))
+	}
+	bad := struct {
+		A int `tabindex:"first"`
+	}{}
+	if _, e := htmlctrl.Struct(&bad, "tabindex", "", ""); e == nil {
+		logError("tabindex: expected an error for a tabindex that isn't an int")
+	}
+	logInfo("end testTabIndex")
+}
+
+type accessorTemp struct {
+	celsius float64
+}
+
+func (t *accessorTemp) Celsius() float64 {
+	return t.celsius
+}
+
+func (t *accessorTemp) SetCelsius(c float64) error {
+	if c < -273.15 {
+		return fmt.Errorf("below absolute zero")
+	}
+	t.celsius = c
+	return nil
+}
+
+func testAccessor(body jquery.JQuery) {
+	logInfo("begin testAccessor")
+	temp := &accessorTemp{20}
+	get := func() interface{} {
+		return temp.Celsius()
+	}
+	set := func(v interface{}) error {
+		return temp.SetCelsius(v.(float64))
+	}
+	j, e := htmlctrl.Accessor(get, set, reflect.Float64, "accessor", "", "", nil)
+	if e != nil {
+		logError(fmt.Sprintf("accessor: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	if j.Val() != "20" {
+		logError(fmt.Sprintf("accessor: shows %s, expected 20", j.Val()))
+	}
+	changes := 0
+	htmlctrl.OnChange(j, func(old, new interface{}) {
+		changes++
+	})
+	j.SetVal(-300).Trigger(jquery.CHANGE)
+	if temp.celsius != 20 || j.Val() != "20" || !j.HasClass("go-invalid") || changes != 0 {
+		logError(fmt.Sprintf("accessor: failed set left %g, shown as %s, with %d changes", temp.celsius, j.Val(),
+			changes))
+	}
+	j.SetVal(25.5).Trigger(jquery.CHANGE)
+	if temp.celsius != 25.5 || j.HasClass("go-invalid") || changes != 1 {
+		logError(fmt.Sprintf("accessor: set left %g with %d changes, expected 25.5 with 1", temp.celsius, changes))
+	}
+	// set isn't called for values that a setter from WithSetter doesn't store
+	below30 := htmlctrl.WithSetter(func(path string, value interface{}, store func()) {
+		if value.(float64) < 30 {
+			store()
+		}
+	})
+	j, e = htmlctrl.Accessor(get, set, reflect.Float64, "accessor setter", "", "", nil, below30)
+	if e != nil {
+		logError(fmt.Sprintf("accessor setter: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	j.SetVal(35).Trigger(jquery.CHANGE)
+	if temp.celsius != 25.5 {
+		logError(fmt.Sprintf("accessor setter: set was called with %g, which the setter didn't store", temp.celsius))
+	}
+	j.SetVal(28).Trigger(jquery.CHANGE)
+	if temp.celsius != 28 {
+		logError(fmt.Sprintf("accessor setter: value is %g, expected 28", temp.celsius))
+	}
+	if _, e := htmlctrl.Accessor(get, set, reflect.Int, "accessor", "", "", nil); e != nil {
+		logError(fmt.Sprintf("accessor: unexpected error converting float64 to int: %s", e))
+	}
+	if _, e := htmlctrl.Accessor(get, set, reflect.Map, "accessor", "", "", nil); e == nil {
+		logError("accessor: expected an error for an unsupported kind")
+	}
+	logInfo("end testAccessor")
+}
+
+func testChangeEvent(body jquery.JQuery) {
+	logInfo("begin testChangeEvent")
+	type form struct {
+		Name string
+		Sub  struct {
+			Count int
+		}
+	}
+	var a, b form
+	ja, e := htmlctrl.Struct(&a, "a", "form-a", "")
+	if e != nil {
+		logError(fmt.Sprintf("change event: unexpected error: %s", e))
+		return
+	}
+	jb, e := htmlctrl.Struct(&b, "b", "form-b", "")
+	if e != nil {
+		logError(fmt.Sprintf("change event: unexpected error: %s", e))
+		return
+	}
+	container := jq("<div>").Append(ja).Append(jb)
+	body.Append(container)
+	var got []string
+	container.On(htmlctrl.ChangeEvent, func(event *js.Object, path string) {
+		if event.Get("namespace").String() != htmlctrl.EventNamespace {
+			return
+		}
+		got = append(got, event.Get("target").Call("closest", "[id^=form-]").Get("id").String()+" "+path)
+	})
+	jb.Find(".go-int").SetVal(3).Trigger(jquery.CHANGE)
+	ja.Find(".go-string").SetVal("x").Trigger(jquery.CHANGE)
+	expected := []string{"form-b Sub.Count", "form-a Name"}
+	if fmt.Sprint(got) != fmt.Sprint(expected) {
+		logError(fmt.Sprintf("change event: got %q, expected %q", got, expected))
+	}
+	container.Off("." + htmlctrl.EventNamespace)
+	logInfo("end testChangeEvent")
+}
+
+func testStrictMode(body jquery.JQuery) {
+	logInfo("begin testStrictMode")
+	form := struct {
+		A   int
+		C   chan int
+		D   chan string
+		Sub struct {
+			E chan bool
+			F string
+		}
+	}{}
+	expected := "C: unsupported type chan; D: unsupported type chan; Sub.E: unsupported type chan"
+	_, e := htmlctrl.Struct(&form, "unsupported", "", "", htmlctrl.StrictMode())
+	if e == nil || e.Error() != expected {
+		logError(fmt.Sprintf("unsupported: got error '%v' in strict mode, expected '%s'", e, expected))
+	}
+	j, e := htmlctrl.Struct(&form, "unsupported", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("unsupported: unexpected error while skipping: %s", e))
+		return
+	}
+	body.Append(j)
+	if n := j.Find(".go-struct-field").Length; n != 3 {
+		logError(fmt.Sprintf("unsupported: %d fields shown, expected 3", n))
+	}
+	logInfo("end testStrictMode")
+}
+
+func testInterfaceSlice(body jquery.JQuery) {
+	logInfo("begin testInterfaceSlice")
+	items := []interface{}{1, "a", true, 2.5}
+	j, e := htmlctrl.Slice(&items, "interface slice", "", "", math.NaN(), math.NaN(), math.NaN(), nil,
+		htmlctrl.WithPrototype("new"))
+	if e != nil {
+		logError(fmt.Sprintf("interface slice: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	j.Find(".go-int").SetVal(5).Trigger(jquery.CHANGE)
+	j.Find(".go-string").SetVal("b").Trigger(jquery.CHANGE)
+	j.Find(".go-bool").SetProp("checked", false).Trigger(jquery.CHANGE)
+	j.Find(".go-float64").SetVal(3.5).Trigger(jquery.CHANGE)
+	expected := []interface{}{5, "b", false, 3.5}
+	if !reflect.DeepEqual(items, expected) {
+		logError(fmt.Sprintf("interface slice: got %v, expected %v", items, expected))
+	}
+	j.Find(".go-slice-add button").Trigger(jquery.CLICK)
+	if len(items) != 5 || items[4] != "new" {
+		logError(fmt.Sprintf("interface slice: got %v after adding, expected a new element of \"new\"", items))
+	}
+	if n := j.Find(".go-string").Length; n != 2 {
+		logError(fmt.Sprintf("interface slice: %d strings shown after adding, expected 2", n))
+	}
+
+	var noProto []interface{}
+	j, e = htmlctrl.Slice(&noProto, "interface slice", "", "", math.NaN(), math.NaN(), math.NaN(), nil)
+	if e != nil {
+		logError(fmt.Sprintf("interface slice: unexpected error without a prototype: %s", e))
+		return
+	}
+	if j.Find(".go-slice-add").Length != 0 {
+		logError("interface slice: expected no add button without a prototype")
+	}
+	logInfo("end testInterfaceSlice")
+}
+
+func testTimeAgo(body jquery.JQuery) {
+	logInfo("begin testTimeAgo")
+	form := struct {
+		Created time.Time `widget:"ago"`
+		Never   time.Time `widget:"ago"`
+	}{Created: time.Now().Add(-3*24*time.Hour - time.Hour)}
+	j, e := htmlctrl.Struct(&form, "ago", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("ago: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	texts := j.Find(".go-time-ago-text")
+	if got := texts.Eq(0).Text(); got != "3 days ago" {
+		logError(fmt.Sprintf("ago: shows '%s', expected '3 days ago'", got))
+	}
+	if got := texts.Eq(1).Text(); got != "Never" {
+		logError(fmt.Sprintf("ago: shows '%s' for the zero time, expected 'Never'", got))
+	}
+	input := j.Find(".go-time").Eq(0)
+	if input.Is(":visible") {
+		logError("ago: input is visible before the text is clicked")
+	}
+	texts.Eq(0).Trigger(jquery.CLICK)
+	if !input.Is(":visible") || texts.Eq(0).Is(":visible") {
+		logError("ago: clicking the text didn't switch to the input")
+	}
+	edited := time.Now().Add(-2 * time.Hour).Truncate(time.Minute)
+	input.SetVal(edited.Format("2006-01-02T15:04")).Trigger(jquery.CHANGE).Trigger(jquery.BLUR)
+	if !form.Created.Equal(edited) {
+		logError(fmt.Sprintf("ago: Created is %s, expected %s", form.Created, edited))
+	}
+	if got := texts.Eq(0).Text(); got != "2 hours ago" || input.Is(":visible") {
+		logError(fmt.Sprintf("ago: shows '%s' after editing, expected the text '2 hours ago'", got))
+	}
+	labels := htmlctrl.DefaultLabels()
+	labels.TimeAgo = func(d time.Duration) string {
+		return fmt.Sprintf("vor %d Stunden", int(d.Hours()))
+	}
+	created := time.Now().Add(-5*time.Hour - time.Minute)
+	j, e = htmlctrl.TimeAgo(&created, "ago labels", "", "", time.Time{}, time.Time{}, nil, htmlctrl.WithLabels(labels))
+	if e != nil {
+		logError(fmt.Sprintf("ago labels: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	if got := j.Find(".go-time-ago-text").Text(); got != "vor 5 Stunden" {
+		logError(fmt.Sprintf("ago labels: shows '%s', expected the TimeAgo label's 'vor 5 Stunden'", got))
+	}
+	logInfo("end testTimeAgo")
+}
+
+func testSliceEmptyText(body jquery.JQuery) {
+	logInfo("begin testSliceEmptyText")
+	form := struct {
+		Items []string          `emptytext:"No items yet"`
+		Rows  []struct{ A int } `table:"true" emptytext:"No rows"`
+	}{}
+	j, e := htmlctrl.Struct(&form, "empty", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("empty: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	empty := j.Find(".go-slice-empty")
+	if empty.Length != 2 || empty.Eq(0).Text() != "No items yet" || empty.Eq(1).Text() != "No rows" {
+		logError(fmt.Sprintf("empty: found %d placeholders, expected 'No items yet' and 'No rows'", empty.Length))
+	}
+	list := j.Find(".go-slice").Eq(0)
+	list.Find(".go-slice-add button").Trigger(jquery.CLICK)
+	if list.Find(".go-slice-empty").Length != 0 {
+		logError("empty: placeholder is still shown after adding an element")
+	}
+	list.Find("li button").Eq(0).Trigger(jquery.CLICK)
+	if len(form.Items) != 0 || list.Find(".go-slice-empty").Length != 1 {
+		logError(fmt.Sprintf("empty: placeholder isn't back after deleting the element, items are %v", form.Items))
+	}
+	logInfo("end testSliceEmptyText")
+}
+
+func testSliceChoices(body jquery.JQuery) {
+	logInfo("begin testSliceChoices")
+	form := struct {
+		Colors []string `choice:"red,green,blue"`
+	}{[]string{"green", "blue"}}
+	j, e := htmlctrl.Struct(&form, "slice choices", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("slice choices: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	selects := j.Find("select.go-choice")
+	if selects.Length != 2 {
+		logError(fmt.Sprintf("slice choices: found %d selects, expected 2", selects.Length))
+		return
+	}
+	selects.Eq(1).SetVal("red").Trigger(jquery.CHANGE)
+	j.Find(".go-slice-add button").Trigger(jquery.CLICK)
+	expected := []string{"green", "red", "red"}
+	if fmt.Sprint(form.Colors) != fmt.Sprint(expected) {
+		logError(fmt.Sprintf("slice choices: got %v, expected %v", form.Colors, expected))
+	}
+	bad := struct {
+		Counts []int `choice:"1,2"`
+	}{}
+	if _, e := htmlctrl.Struct(&bad, "slice choices", "", ""); e == nil {
+		logError("slice choices: expected an error for choices on a slice of ints")
+	}
+	logInfo("end testSliceChoices")
+}
+
+func testDirty(body jquery.JQuery) {
+	logInfo("begin testDirty")
+	form := struct {
+		Name string
+		Tags []string
+		Save func()
+	}{Name: "a", Save: func() {}}
+	j, e := htmlctrl.Struct(&form, "dirty", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("dirty: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	dirty := htmlctrl.TrackDirty(j, &form)
+	if dirty.IsDirty() {
+		logError("dirty: dirty before any change")
+	}
+	name := j.Find(".go-string").Eq(0)
+	name.SetVal("b").Trigger(jquery.CHANGE)
+	if !dirty.IsDirty() || !j.HasClass("go-dirty") {
+		logError("dirty: not dirty after changing Name")
+	}
+	name.SetVal("a").Trigger(jquery.CHANGE)
+	if dirty.IsDirty() || j.HasClass("go-dirty") {
+		logError("dirty: still dirty after changing Name back")
+	}
+	tags := j.Find(".go-slice")
+	tags.Find(".go-slice-add button").Trigger(jquery.CLICK)
+	if !dirty.IsDirty() {
+		logError("dirty: not dirty after adding a tag")
+	}
+	tags.Find("li button").Eq(0).Trigger(jquery.CLICK)
+	if dirty.IsDirty() {
+		logError(fmt.Sprintf("dirty: still dirty after deleting the added tag, tags are %#v", form.Tags))
+	}
+	name.SetVal("c").Trigger(jquery.CHANGE)
+	dirty.Reset()
+	if dirty.IsDirty() || j.HasClass("go-dirty") {
+		logError("dirty: still dirty after Reset")
+	}
+	logInfo("end testDirty")
+}
+
+func testAutocomplete(body jquery.JQuery) {
+	logInfo("begin testAutocomplete")
+	form := struct {
+		Email string  `autocomplete:"email"`
+		Token string  `widget:"secret" autocomplete:"off"`
+		Age   int     `autocomplete:"off"`
+		Rate  float64 `autocomplete:"off"`
+		Note  string
+	}{}
+	j, e := htmlctrl.Struct(&form, "autocomplete", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("autocomplete: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	cases := []struct {
+		selector string
+		expected string
+	}{
+		{".go-string:eq(0)", "email"},
+		{".go-secret input", "off"},
+		{".go-int", "off"},
+		{".go-float64", "off"},
+		{".go-string:last", ""},
+	}
+	for _, c := range cases {
+		if got := j.Find(c.selector).Attr("autocomplete"); got != c.expected {
+			logError(fmt.Sprintf("autocomplete: %s has '%s', expected '%s'", c.selector, got, c.expected))
+		}
+	}
+	s := ""
+	input, e := htmlctrl.String(&s, "autocomplete", "", "", nil, htmlctrl.WithAutocomplete("username"))
+	if e != nil {
+		logError(fmt.Sprintf("autocomplete: unexpected error: %s", e))
+		return
+	}
+	if got := input.Attr("autocomplete"); got != "username" {
+		logError(fmt.Sprintf("autocomplete: String has '%s', expected 'username'", got))
+	}
+	logInfo("end testAutocomplete")
+}
+
+func testInjectDefaultCSS(body jquery.JQuery) {
+	logInfo("begin testInjectDefaultCSS")
+	htmlctrl.InjectDefaultCSS()
+	htmlctrl.InjectDefaultCSS()
+	style := jq("head style#go-default-css")
+	if style.Length != 1 {
+		logError(fmt.Sprintf("default css: found %d style tags, expected 1", style.Length))
+		return
+	}
+	if !strings.Contains(style.Text(), ".go-struct {") {
+		logError("default css: expected the style to use ClassPrefix")
+	}
+	// Leave the page styled by the test style sheet alone
+	style.Remove()
+	logInfo("end testInjectDefaultCSS")
+}
+
+func testStructRender(body jquery.JQuery) {
+	logInfo("begin testStructRender")
+	type address struct {
+		Street string
+		City   string
+	}
+	form := struct {
+		Name    string
+		Home    *address `render:"inline"`
+		Billing *address `render:"section"`
+	}{"a", &address{"1 Main", "Here"}, &address{"2 Side", "There"}}
+	j, e := htmlctrl.Struct(&form, "render", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("render: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	// Home's fields are promoted next to Name, Billing is in a section of its own
+	labels := j.Children(".go-struct-field").Children("label")
+	var names []string
+	for i := 0; i < labels.Length; i++ {
+		names = append(names, labels.Eq(i).Text())
+	}
+	if expected := "[Name Street City]"; fmt.Sprint(names) != expected {
+		logError(fmt.Sprintf("render: top level fields are %v, expected %s", names, expected))
+	}
+	section := j.Children("details.go-struct-section")
+	if section.Length != 1 || section.Children("summary").Text() != "Billing" || !section.Prop("open").(bool) {
+		logError("render: expected an open section for Billing")
+		return
+	}
+	j.Children(".go-struct-field").Eq(1).Find(".go-string").SetVal("3 Main").Trigger(jquery.CHANGE)
+	section.Find(".go-string").Eq(1).SetVal("Elsewhere").Trigger(jquery.CHANGE)
+	if form.Home.Street != "3 Main" || form.Billing.City != "Elsewhere" {
+		logError(fmt.Sprintf("render: Home is %v and Billing is %v after editing", *form.Home, *form.Billing))
+	}
+	bad := struct {
+		N int `render:"inline"`
+	}{}
+	if _, e := htmlctrl.Struct(&bad, "render", "", ""); e == nil {
+		logError("render: expected an error for render on an int")
+	}
+	logInfo("end testStructRender")
+}
+
+func testUnit(body jquery.JQuery) {
+	logInfo("begin testUnit")
+	form := struct {
+		Size  int     `unit:"MB"`
+		Price float64 `prefix:"$"`
+		Count int
+	}{5, 2.5, 1}
+	j, e := htmlctrl.Struct(&form, "unit", "", "")
+	if e != nil {
+		logError(fmt.Sprintf("unit: unexpected error: %s", e))
+		return
+	}
+	body.Append(j)
+	size := j.Find(".go-int").Eq(0)
+	if next := size.Next(); !next.HasClass("go-unit") || next.Text() != "MB" {
+		logError(fmt.Sprintf("unit: expected 'MB' after the input, got '%s'", next.Text()))
+	}
+	price := j.Find(".go-float64")
+	if prev := price.Prev(); !prev.HasClass("go-unit-prefix") || prev.Text() != "$" {
+		logError(fmt.Sprintf("unit: expected '$' before the input, got '%s'", prev.Text()))
+	}
+	if n := j.Find(".go-unit-wrap").Length; n != 2 {
+		logError(fmt.Sprintf("unit: found %d wrapped inputs, expected 2", n))
+	}
+	size.SetVal(10).Trigger(jquery.CHANGE)
+	if form.Size != 10 {
+		logError(fmt.Sprintf("unit: Size is %d, expected 10", form.Size))
+	}
+	logInfo("end testUnit")
+}